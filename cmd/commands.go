@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/shop"
+)
+
+// initTimeout is the max duration for creating all topics, schemas and ACLs.
+const initTimeout = time.Minute
+
+func runCmd(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	cfgFlags := registerConfigFlags(fs)
+	dryRun := fs.Bool("dry-run", false,
+		"Print all generated records to stdout instead of producing them. Topics and schemas are not created.")
+	dryRunFormat := fs.String("dry-run.format", "",
+		"Output format of the records in dry-run mode. Valid values are: text, json (default text)")
+	_ = fs.Parse(args)
+
+	cfg, err := cfgFlags.loadConfig()
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		cfg.Sink.Type = config.SinkTypeStdout
+		if *dryRunFormat != "" {
+			cfg.Sink.Stdout.Format = *dryRunFormat
+		}
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("failed to validate config: %w", err)
+	}
+	logger := newLogger(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shopSvc, err := newInitializedShop(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	err = shopSvc.Start(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to start shop: %w", err)
+	}
+
+	return nil
+}
+
+func initCmd(args []string) error {
+	fs := flag.NewFlagSet("init", flag.ExitOnError)
+	cfgFlags := registerConfigFlags(fs)
+	_ = fs.Parse(args)
+
+	cfg, err := cfgFlags.loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("failed to validate config: %w", err)
+	}
+	logger := newLogger(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	shopSvc, err := newInitializedShop(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+
+	closeCtx, cancel := context.WithTimeout(context.Background(), cfg.Shop.DrainTimeout)
+	defer cancel()
+	if err := shopSvc.Close(closeCtx); err != nil {
+		return fmt.Errorf("failed to close shop: %w", err)
+	}
+	logger.Info("successfully initialized shop")
+
+	return nil
+}
+
+func teardownCmd(args []string) error {
+	fs := flag.NewFlagSet("teardown", flag.ExitOnError)
+	cfgFlags := registerConfigFlags(fs)
+	dryRun := fs.Bool("dry-run", false, "Only list the resources that would be deleted")
+	yes := fs.Bool("yes", false, "Delete the resources without asking for confirmation")
+	_ = fs.Parse(args)
+
+	cfg, err := cfgFlags.loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("failed to validate config: %w", err)
+	}
+	logger := newLogger(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	teardown, err := shop.NewTeardown(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create teardown: %w", err)
+	}
+	defer teardown.Close()
+
+	plan, err := teardown.Plan(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to discover resources: %w", err)
+	}
+	plan.Print(os.Stdout)
+	if plan.IsEmpty() || *dryRun {
+		return nil
+	}
+
+	if !*yes {
+		fmt.Print("\nDelete all listed resources? [y/N]: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("Aborted, no resources have been deleted")
+			return nil
+		}
+	}
+
+	if err := teardown.Execute(ctx, plan); err != nil {
+		return fmt.Errorf("failed to delete all resources: %w", err)
+	}
+	logger.Info("successfully deleted all resources")
+
+	return nil
+}
+
+func checkCompatibilityCmd(args []string) error {
+	fs := flag.NewFlagSet("check-compatibility", flag.ExitOnError)
+	cfgFlags := registerConfigFlags(fs)
+	_ = fs.Parse(args)
+
+	cfg, err := cfgFlags.loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("failed to validate config: %w", err)
+	}
+	logger := newLogger(cfg)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	check, err := shop.NewCompatibilityCheck(cfg, logger)
+	if err != nil {
+		return fmt.Errorf("failed to create compatibility check: %w", err)
+	}
+
+	// The incompatible changes are registered against the shop's subjects
+	shopSvc, err := newInitializedShop(ctx, cfg, logger)
+	if err != nil {
+		return err
+	}
+	closeCtx, cancel := context.WithTimeout(context.Background(), cfg.Shop.DrainTimeout)
+	defer cancel()
+	if err := shopSvc.Close(closeCtx); err != nil {
+		return fmt.Errorf("failed to close shop: %w", err)
+	}
+
+	report, err := check.Run(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check compatibility: %w", err)
+	}
+	report.Print(os.Stdout)
+	if mismatches := report.Mismatches(); mismatches > 0 {
+		return fmt.Errorf("%d verdicts differ from the configured compatibility levels", mismatches)
+	}
+
+	return nil
+}
+
+func validateConfigCmd(args []string) error {
+	fs := flag.NewFlagSet("validate-config", flag.ExitOnError)
+	cfgFlags := registerConfigFlags(fs)
+	_ = fs.Parse(args)
+
+	cfg, err := cfgFlags.loadConfig()
+	if err != nil {
+		return err
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config is invalid: %w", err)
+	}
+	fmt.Println("config is valid")
+
+	return nil
+}
+
+func printConfigCmd(args []string) error {
+	fs := flag.NewFlagSet("print-config", flag.ExitOnError)
+	cfgFlags := registerConfigFlags(fs)
+	_ = fs.Parse(args)
+
+	cfg, err := cfgFlags.loadConfig()
+	if err != nil {
+		return err
+	}
+
+	out, err := cfg.Redacted().YAML()
+	if err != nil {
+		return fmt.Errorf("failed to serialize config: %w", err)
+	}
+	_, err = os.Stdout.Write(out)
+
+	return err
+}
+
+// newInitializedShop creates the shop and all resources that are required by it.
+func newInitializedShop(ctx context.Context, cfg config.Config, logger *zap.Logger) (*shop.Shop, error) {
+	shopSvc, err := shop.New(cfg, logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shop: %w", err)
+	}
+
+	initCtx, cancel := context.WithTimeout(ctx, initTimeout)
+	defer cancel()
+	if err := shopSvc.Initialize(initCtx); err != nil {
+		// Release the clients and flush the sinks of the uninitialized shop
+		closeCtx, cancelClose := context.WithTimeout(context.Background(), cfg.Shop.DrainTimeout)
+		defer cancelClose()
+		if closeErr := shopSvc.Close(closeCtx); closeErr != nil {
+			logger.Warn("failed to close shop", zap.Error(closeErr))
+		}
+		return nil, fmt.Errorf("failed to initialize shop: %w", err)
+	}
+
+	return shopSvc, nil
+}
@@ -1,28 +1,151 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/cloudhut/common/logging"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"github.com/cloudhut/owl-shop/pkg/config"
-	"github.com/cloudhut/owl-shop/pkg/shop"
 )
 
+// command is a subcommand of the owl-shop CLI.
+type command struct {
+	name        string
+	description string
+	// run executes the command with the remaining arguments after the command name.
+	run func(args []string) error
+}
+
+var commands = []command{
+	{name: "run", description: "Initialize the shop and simulate traffic (default)", run: runCmd},
+	{name: "init", description: "Create all topics, schemas and ACLs, then exit", run: initCmd},
+	{name: "teardown", description: "Delete the topics, consumer groups, ACLs and schemas of the shop with the global prefix", run: teardownCmd},
+	{name: "check-compatibility", description: "Initialize the shop, then attempt incompatible customer schema registrations and report the registry's verdicts", run: checkCompatibilityCmd},
+	{name: "validate-config", description: "Validate the config, then exit", run: validateConfigCmd},
+	{name: "print-config", description: "Print the effective config with secrets redacted, then exit", run: printConfigCmd},
+}
+
 func main() {
-	startupLogger := zap.NewExample()
-	cfg, err := config.LoadConfig(startupLogger)
-	if err != nil {
-		startupLogger.Fatal("failed to parse config", zap.Error(err))
+	// Without a command (or if the first argument is a flag) the shop is run,
+	// so that invocations from before the introduction of commands keep working.
+	args := os.Args[1:]
+	name := "run"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
 	}
 
-	logger := logging.NewLogger(&cfg.Logger, "owl_shop")
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(args); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
-	shopSvc, err := shop.New(cfg, logger)
-	if err != nil {
-		logger.Fatal("failed to initialize shop", zap.Error(err))
+	if name != "help" {
+		fmt.Fprintf(os.Stderr, "Unknown command '%v'\n\n", name)
+	}
+	printUsage()
+	if name != "help" {
+		os.Exit(2)
+	}
+}
+
+func printUsage() {
+	fmt.Fprintf(os.Stderr, "Usage: %v <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16v %v\n", cmd.name, cmd.description)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%v <command> -h' to list the flags of a command.\n", os.Args[0])
+}
+
+// overrideFlags are config keys that can be set via dedicated flags, e.g. so
+// that secrets don't have to be put into the YAML config.
+var overrideFlags = []string{
+	"kafka.brokers",
+	"kafka.sasl.password",
+	"kafka.sasl.gssapi.password",
+	"kafka.sasl.oauth.token",
+	"kafka.sasl.oauth.clientSecret",
+	"schemaRegistry.basicAuth.password",
+	"shop.globalPrefix",
+	"logger.level",
+}
+
+// configFlags are the flags that are shared by all commands to load the config.
+type configFlags struct {
+	configFilepath string
+	overrides      map[string]string
+}
+
+func registerConfigFlags(fs *flag.FlagSet) *configFlags {
+	flags := &configFlags{overrides: make(map[string]string)}
+
+	fs.StringVar(&flags.configFilepath, "config.filepath", "",
+		"Path to the YAML config. Defaults to the CONFIG_FILEPATH env variable")
+	for _, key := range overrideFlags {
+		fs.Func(key, fmt.Sprintf("Overrides the config key '%v'", key), func(value string) error {
+			flags.overrides[key] = value
+			return nil
+		})
 	}
-	err = shopSvc.Start()
+	fs.Func("set", "Overrides any config key, e.g. -set shop.requestRate=100. Can be specified multiple times",
+		func(value string) error {
+			key, val, ok := strings.Cut(value, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("expected key=value, but got '%v'", value)
+			}
+			flags.overrides[key] = val
+			return nil
+		})
+
+	return flags
+}
+
+// loadConfig loads the config using the given flags. The config is not validated.
+func (f *configFlags) loadConfig() (config.Config, error) {
+	cfg, err := config.LoadConfig(newStartupLogger(), f.configFilepath, f.overrides)
 	if err != nil {
-		logger.Fatal("failed to start shop", zap.Error(err))
+		return config.Config{}, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// newStartupLogger returns the logger used while loading the config. It writes
+// to stderr, so that the output of commands that print to stdout is not polluted.
+func newStartupLogger() *zap.Logger {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	return zap.New(zapcore.NewCore(zapcore.NewJSONEncoder(encoderCfg), zapcore.Lock(os.Stderr), zap.InfoLevel))
+}
+
+// newLogger creates the logger for the given config.
+func newLogger(cfg config.Config) *zap.Logger {
+	logger := logging.NewLogger(&cfg.Logger, "owl_shop")
+	if cfg.Sink.Type == config.SinkTypeStdout {
+		logger = logToStderr(logger, cfg.Logger.LogLevel)
 	}
+
+	return logger
+}
+
+// logToStderr returns a logger that writes to stderr instead of stdout, so
+// that log messages are not mixed up with the records printed in dry-run mode.
+func logToStderr(logger *zap.Logger, level zapcore.LevelEnabler) *zap.Logger {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	return logger.WithOptions(zap.WrapCore(func(zapcore.Core) zapcore.Core {
+		return zapcore.NewCore(zapcore.NewJSONEncoder(encoderCfg), zapcore.Lock(os.Stderr), level)
+	}))
 }
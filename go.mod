@@ -3,7 +3,7 @@ module github.com/cloudhut/owl-shop
 go 1.22
 
 require (
-	github.com/brianvoe/gofakeit/v5 v5.11.2
+	github.com/brianvoe/gofakeit/v6 v6.28.0
 	github.com/cloudhut/common v0.10.0
 	github.com/hamba/avro/v2 v2.20.0
 	github.com/jcmturner/gokrb5/v8 v8.4.4
@@ -19,12 +19,14 @@ require (
 	github.com/twmb/tlscfg v1.2.1
 	go.uber.org/zap v1.27.0
 	google.golang.org/protobuf v1.33.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/beorn7/perks v1.0.1 // indirect
 	github.com/cespare/xxhash/v2 v2.2.0 // indirect
 	github.com/fsnotify/fsnotify v1.7.0 // indirect
+	github.com/golang/snappy v0.0.4 // indirect
 	github.com/hashicorp/go-uuid v1.0.3 // indirect
 	github.com/jcmturner/aescts/v2 v2.0.0 // indirect
 	github.com/jcmturner/dnsutils/v2 v2.0.0 // indirect
@@ -45,5 +47,4 @@ require (
 	golang.org/x/crypto v0.21.0 // indirect
 	golang.org/x/net v0.22.0 // indirect
 	golang.org/x/sys v0.18.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
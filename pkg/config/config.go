@@ -7,6 +7,7 @@ import (
 	"github.com/cloudhut/common/logging"
 	"github.com/knadh/koanf"
 	"github.com/knadh/koanf/parsers/yaml"
+	"github.com/knadh/koanf/providers/confmap"
 	"github.com/knadh/koanf/providers/env"
 	"github.com/knadh/koanf/providers/file"
 	"github.com/mitchellh/mapstructure"
@@ -21,12 +22,15 @@ type Config struct {
 	Kafka          Kafka          `yaml:"kafka"`
 	SchemaRegistry SchemaRegistry `yaml:"schemaRegistry"`
 	Shop           Shop           `yaml:"shop"`
+	Sink           Sink           `yaml:"sink"`
 }
 
 func (c *Config) SetDefaults() {
 	c.Logger.SetDefaults()
 	c.Kafka.SetDefaults()
+	c.SchemaRegistry.SubjectNameStrategy = SubjectNameStrategyTopicName
 	c.Shop.SetDefaults()
+	c.Sink.SetDefaults()
 }
 
 func (c *Config) Validate() error {
@@ -34,26 +38,60 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("failed to validate loglevel input: %w", err)
 	}
 
-	if err := c.Kafka.Validate(); err != nil {
-		return fmt.Errorf("failed to validate Kafka config: %w", err)
+	if err := c.Sink.Validate(); err != nil {
+		return fmt.Errorf("failed to validate sink config: %w", err)
+	}
+
+	// Kafka is not required if records are not written to Kafka
+	if c.Sink.Type == SinkTypeKafka {
+		if err := c.Kafka.Validate(); err != nil {
+			return fmt.Errorf("failed to validate Kafka config: %w", err)
+		}
+	}
+
+	if err := c.SchemaRegistry.Validate(); err != nil {
+		return fmt.Errorf("failed to validate schema registry config: %w", err)
+	}
+
+	if err := c.Shop.Validate(); err != nil {
+		return fmt.Errorf("failed to validate shop config: %w", err)
+	}
+
+	// Without Kafka, schemas are registered in memory if no schema registry is configured
+	if c.Shop.Keys.IsSchemaRegistryFormat() && c.Sink.Type == SinkTypeKafka && c.SchemaRegistry.Address == "" {
+		return fmt.Errorf("key format '%v' requires a schema registry", c.Shop.Keys.Format)
+	}
+
+	if len(c.Shop.SchemaEvolution.Steps) > 0 && c.Sink.Type == SinkTypeKafka && c.SchemaRegistry.Address == "" {
+		return fmt.Errorf("schema evolution requires a schema registry")
+	}
+
+	if c.Shop.TransactionalOrders {
+		if c.Sink.Type != SinkTypeKafka {
+			return fmt.Errorf("transactional orders require the '%v' sink", SinkTypeKafka)
+		}
+		if !c.Kafka.Producer.Idempotence {
+			return fmt.Errorf("transactional orders require an idempotent producer")
+		}
 	}
 
 	return nil
 }
 
-func LoadConfig(logger *zap.Logger) (Config, error) {
+// LoadConfig loads the config from the YAML file, environment variables and the
+// given overrides, with the latter taking precedence. The overrides are keyed by
+// the config key path (e.g. "kafka.sasl.password"), usually they are set via flags.
+func LoadConfig(logger *zap.Logger, configFilepath string, overrides map[string]string) (Config, error) {
 	k := koanf.New(".")
 	var cfg Config
 	cfg.SetDefaults()
 
 	// 1. Check if a config filepath is set via flags. If there is one we'll try to load the file using a YAML Parser
-	var configFilepath string
-	if cfg.ConfigFilepath != "" {
-		configFilepath = cfg.ConfigFilepath
-	} else {
+	if configFilepath == "" {
 		envKey := "CONFIG_FILEPATH"
 		configFilepath = os.Getenv(envKey)
 	}
+	cfg.ConfigFilepath = configFilepath
 	if configFilepath == "" {
 		logger.Info("config filepath is not set, proceeding with options set from env variables and flags")
 	} else {
@@ -98,5 +136,29 @@ func LoadConfig(logger *zap.Logger) (Config, error) {
 		return Config{}, err
 	}
 
+	// 3. Apply the overrides. Unlike env variables, overrides must refer to existing config keys.
+	if len(overrides) > 0 {
+		flattened := make(map[string]any, len(overrides))
+		for key, value := range overrides {
+			flattened[key] = value
+		}
+		ko := koanf.New(".")
+		err = ko.Load(confmap.Provider(flattened, "."), nil)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to load config overrides: %w", err)
+		}
+		unmarshalCfg.DecoderConfig.ErrorUnused = true
+		err = ko.UnmarshalWithConf("", &cfg, unmarshalCfg)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to unmarshal config overrides into config struct: %w", err)
+		}
+	}
+
+	// 4. Environment variables and overrides may refer to actions and payment
+	// methods with a different case
+	cfg.Shop.EventMix = cfg.Shop.EventMix.Normalized()
+	cfg.Shop.MaxEvents.Actions = normalizeActions(cfg.Shop.MaxEvents.Actions)
+	cfg.Shop.Payments = cfg.Shop.Payments.Normalized()
+
 	return cfg, nil
 }
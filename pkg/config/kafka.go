@@ -9,6 +9,8 @@ type Kafka struct {
 	Brokers []string `yaml:"brokers"`
 	TLS     TLS      `yaml:"tls"`
 	SASL    SASL     `yaml:"sasl"`
+
+	Producer KafkaProducer `yaml:"producer"`
 }
 
 // Validate Kafka config.
@@ -22,10 +24,16 @@ func (c *Kafka) Validate() error {
 		return fmt.Errorf("failed to validate SASL config: %w", err)
 	}
 
+	err = c.Producer.Validate()
+	if err != nil {
+		return fmt.Errorf("failed to validate producer config: %w", err)
+	}
+
 	return nil
 }
 
 // SetDefaults for Kafka config
 func (c *Kafka) SetDefaults() {
 	c.SASL.SetDefaults()
+	c.Producer.SetDefaults()
 }
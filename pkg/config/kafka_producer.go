@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	ProducerCompressionNone   = "none"
+	ProducerCompressionGzip   = "gzip"
+	ProducerCompressionSnappy = "snappy"
+	ProducerCompressionLz4    = "lz4"
+	ProducerCompressionZstd   = "zstd"
+)
+
+const (
+	ProducerAcksNone   = "none"
+	ProducerAcksLeader = "leader"
+	ProducerAcksAll    = "all"
+)
+
+const (
+	// ProducerPartitionerUniformBytes sticks to a partition until a number of
+	// bytes has been produced and hashes keys with murmur2.
+	ProducerPartitionerUniformBytes = "uniformBytes"
+	// ProducerPartitionerSticky sticks to a partition for each batch and
+	// ignores record keys.
+	ProducerPartitionerSticky = "sticky"
+	// ProducerPartitionerRoundRobin produces each record to the next
+	// partition and ignores record keys.
+	ProducerPartitionerRoundRobin = "roundRobin"
+	// ProducerPartitionerMurmur2 hashes record keys with murmur2, the same
+	// way the Java client does. Records without a key are sticky.
+	ProducerPartitionerMurmur2 = "murmur2"
+)
+
+// KafkaProducer configures how records are batched and produced. The defaults
+// match the defaults of the Kafka client.
+type KafkaProducer struct {
+	// Compression codec for record batches. Valid values are: none, gzip,
+	// snappy, lz4, zstd. Defaults to snappy.
+	Compression string `yaml:"compression"`
+
+	// RequiredAcks is the number of acknowledgements the leader must receive
+	// before a produce request is considered successful. Valid values are:
+	// none, leader, all. Defaults to all.
+	RequiredAcks string `yaml:"requiredAcks"`
+
+	// Linger is how long to wait for more records before a partition's batch
+	// is produced. Defaults to 0, which produces as soon as possible.
+	Linger time.Duration `yaml:"linger"`
+
+	// MaxBatchBytes is the maximum size of a record batch. Defaults to
+	// 1000012, which is the default max.message.bytes of a topic.
+	MaxBatchBytes int32 `yaml:"maxBatchBytes"`
+
+	// MaxBufferedRecords is the maximum number of records that are buffered
+	// before producing blocks. Defaults to 10000.
+	MaxBufferedRecords int `yaml:"maxBufferedRecords"`
+
+	// Idempotence enables the idempotent producer, which requires all acks.
+	// Defaults to true.
+	Idempotence bool `yaml:"idempotence"`
+
+	// Partitioner decides the partition of each record. Valid values are:
+	// uniformBytes, sticky, roundRobin, murmur2. Defaults to uniformBytes.
+	Partitioner string `yaml:"partitioner"`
+}
+
+// SetDefaults for the producer config.
+func (c *KafkaProducer) SetDefaults() {
+	c.Compression = ProducerCompressionSnappy
+	c.RequiredAcks = ProducerAcksAll
+	c.MaxBatchBytes = 1000012
+	c.MaxBufferedRecords = 10000
+	c.Idempotence = true
+	c.Partitioner = ProducerPartitionerUniformBytes
+}
+
+// Validate the producer config.
+func (c *KafkaProducer) Validate() error {
+	switch c.Compression {
+	case ProducerCompressionNone, ProducerCompressionGzip, ProducerCompressionSnappy,
+		ProducerCompressionLz4, ProducerCompressionZstd:
+	default:
+		return fmt.Errorf("given compression '%v' is invalid", c.Compression)
+	}
+
+	switch c.RequiredAcks {
+	case ProducerAcksNone, ProducerAcksLeader, ProducerAcksAll:
+	default:
+		return fmt.Errorf("given required acks '%v' is invalid", c.RequiredAcks)
+	}
+	if c.Idempotence && c.RequiredAcks != ProducerAcksAll {
+		return fmt.Errorf("idempotence requires acks '%v', either disable idempotence or change the required acks", ProducerAcksAll)
+	}
+
+	if c.Linger < 0 {
+		return fmt.Errorf("linger must not be a negative duration")
+	}
+
+	if c.MaxBatchBytes <= 0 {
+		return fmt.Errorf("max batch bytes must be a positive integer")
+	}
+
+	if c.MaxBufferedRecords <= 0 {
+		return fmt.Errorf("max buffered records must be a positive integer")
+	}
+
+	switch c.Partitioner {
+	case ProducerPartitionerUniformBytes, ProducerPartitionerSticky,
+		ProducerPartitionerRoundRobin, ProducerPartitionerMurmur2:
+	default:
+		return fmt.Errorf("given partitioner '%v' is invalid", c.Partitioner)
+	}
+
+	return nil
+}
@@ -19,11 +19,13 @@ type SASL struct {
 	Password     string           `yaml:"password"`
 	Mechanism    string           `yaml:"mechanism"`
 	GSSAPIConfig SASLGSSAPIConfig `yaml:"gssapi"`
+	OAuth        SASLOAuthConfig  `yaml:"oauth"`
 }
 
 // SetDefaults for SASL Config
 func (c *SASL) SetDefaults() {
 	c.Mechanism = SASLMechanismPlain
+	c.OAuth.SetDefaults()
 }
 
 // Validate SASL config input
@@ -32,7 +34,12 @@ func (c *SASL) Validate() error {
 	case SASLMechanismPlain, SASLMechanismScramSHA256, SASLMechanismScramSHA512, SASLMechanismGSSAPI:
 		// Valid and supported
 	case SASLMechanismOAuthBearer:
-		return fmt.Errorf("sasl mechanism '%v' is valid but not yet supported. Please submit an issue if you need it", c.Mechanism)
+		if !c.Enabled {
+			break
+		}
+		if err := c.OAuth.Validate(); err != nil {
+			return fmt.Errorf("failed to validate oauth config: %w", err)
+		}
 	default:
 		return fmt.Errorf("given sasl mechanism '%v' is invalid", c.Mechanism)
 	}
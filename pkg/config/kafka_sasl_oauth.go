@@ -0,0 +1,70 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+)
+
+const (
+	SASLOAuthTokenSourceStatic            = "static"
+	SASLOAuthTokenSourceFile              = "file"
+	SASLOAuthTokenSourceClientCredentials = "clientCredentials"
+)
+
+// SASLOAuthConfig represents the Kafka OAUTHBEARER config. The token is either
+// static, read from a file or requested via the OAuth2 client credentials flow.
+type SASLOAuthConfig struct {
+	// TokenSource determines where the token is taken from. Valid values are:
+	// static, file, clientCredentials.
+	TokenSource string `yaml:"tokenSource"`
+
+	// Token that is used if the token source is static.
+	Token string `yaml:"token"`
+
+	// TokenFilepath is the path to the file that contains the token if the
+	// token source is file. The file is read again on every authentication,
+	// so that the token can be rotated by an external process.
+	TokenFilepath string `yaml:"tokenFilepath"`
+
+	// TokenEndpoint, ClientID, ClientSecret and Scopes are used to request
+	// tokens if the token source is clientCredentials. Tokens are cached until
+	// shortly before they expire.
+	TokenEndpoint string   `yaml:"tokenEndpoint"`
+	ClientID      string   `yaml:"clientId"`
+	ClientSecret  string   `yaml:"clientSecret"`
+	Scopes        []string `yaml:"scopes"`
+
+	// Extensions are additional key value pairs that are sent to the broker
+	// when authenticating, e.g. logicalCluster for Confluent Cloud.
+	Extensions map[string]string `yaml:"extensions"`
+}
+
+// SetDefaults for the OAUTHBEARER config.
+func (c *SASLOAuthConfig) SetDefaults() {
+	c.TokenSource = SASLOAuthTokenSourceStatic
+}
+
+// Validate the OAUTHBEARER config.
+func (c *SASLOAuthConfig) Validate() error {
+	switch c.TokenSource {
+	case SASLOAuthTokenSourceStatic:
+		if c.Token == "" {
+			return fmt.Errorf("token must be set if the token source is '%v'", c.TokenSource)
+		}
+	case SASLOAuthTokenSourceFile:
+		if c.TokenFilepath == "" {
+			return fmt.Errorf("token filepath must be set if the token source is '%v'", c.TokenSource)
+		}
+	case SASLOAuthTokenSourceClientCredentials:
+		if _, err := url.ParseRequestURI(c.TokenEndpoint); err != nil {
+			return fmt.Errorf("token endpoint must be a valid URL: %w", err)
+		}
+		if c.ClientID == "" || c.ClientSecret == "" {
+			return fmt.Errorf("client id and client secret must be set if the token source is '%v'", c.TokenSource)
+		}
+	default:
+		return fmt.Errorf("given token source '%v' is invalid", c.TokenSource)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redactedValue replaces secrets when printing the config.
+const redactedValue = "<redacted>"
+
+// Redacted returns a copy of the config with all secrets replaced, so that it
+// can be printed or logged.
+func (c Config) Redacted() Config {
+	redact := func(secret *string) {
+		if *secret != "" {
+			*secret = redactedValue
+		}
+	}
+
+	redact(&c.Kafka.SASL.Password)
+	redact(&c.Kafka.SASL.GSSAPIConfig.Password)
+	redact(&c.Kafka.SASL.OAuth.Token)
+	redact(&c.Kafka.SASL.OAuth.ClientSecret)
+	redact(&c.SchemaRegistry.BasicAuth.Password)
+
+	return c
+}
+
+// YAML returns the config in the same YAML format that it is loaded from.
+// Durations are printed in their human-readable form, so that the output can
+// be used as config file again.
+func (c Config) YAML() ([]byte, error) {
+	node, err := yamlNode(reflect.ValueOf(c))
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(node)
+}
+
+// yamlNode converts the given value into a YAML node. Struct fields are named
+// after their yaml tag, fields without a yaml tag are omitted.
+func yamlNode(v reflect.Value) (*yaml.Node, error) {
+	if d, ok := v.Interface().(time.Duration); ok {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: d.String()}, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+		}
+		return yamlNode(v.Elem())
+	case reflect.Struct:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if !field.IsExported() || name == "" || name == "-" {
+				continue
+			}
+			value, err := yamlNode(v.Field(i))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert field '%v': %w", name, err)
+			}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: name}, value)
+		}
+		return node, nil
+	case reflect.Map:
+		node := &yaml.Node{Kind: yaml.MappingNode}
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+		})
+		for _, key := range keys {
+			value, err := yamlNode(v.MapIndex(key))
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert map entry '%v': %w", key.Interface(), err)
+			}
+			node.Content = append(node.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprint(key.Interface())}, value)
+		}
+		return node, nil
+	case reflect.Slice, reflect.Array:
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		if v.Len() == 0 {
+			node.Style = yaml.FlowStyle
+		}
+		for i := 0; i < v.Len(); i++ {
+			value, err := yamlNode(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, value)
+		}
+		return node, nil
+	}
+
+	node := &yaml.Node{}
+	if err := node.Encode(v.Interface()); err != nil {
+		return nil, err
+	}
+	return node, nil
+}
@@ -1,10 +1,45 @@
 package config
 
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// SubjectNameStrategyTopicName registers value schemas under '<topic>-value'.
+	SubjectNameStrategyTopicName = "TopicNameStrategy"
+	// SubjectNameStrategyRecordName registers schemas under the fully qualified
+	// record name, e.g. 'shop.v1.Customer'.
+	SubjectNameStrategyRecordName = "RecordNameStrategy"
+	// SubjectNameStrategyTopicRecordName registers schemas under
+	// '<topic>-<fully qualified record name>'.
+	SubjectNameStrategyTopicRecordName = "TopicRecordNameStrategy"
+)
+
+// compatibilityLevels are the valid compatibility levels of schema registry subjects.
+var compatibilityLevels = []string{
+	"BACKWARD",
+	"BACKWARD_TRANSITIVE",
+	"FORWARD",
+	"FORWARD_TRANSITIVE",
+	"FULL",
+	"FULL_TRANSITIVE",
+	"NONE",
+}
+
 // SchemaRegistry is the configuration for the schema registry.
 type SchemaRegistry struct {
 	Address   string        `yaml:"address"`
 	BasicAuth HTTPBasicAuth `yaml:"basicAuth"`
 	TLS       TLS           `yaml:"tls"`
+
+	// SubjectNameStrategy determines the subjects under which the value schemas
+	// and the schemas they reference are registered.
+	SubjectNameStrategy string `yaml:"subjectNameStrategy"`
+
+	// Compatibility is the compatibility level that is set on every subject
+	// before schemas are registered. If empty, the registry's default is used.
+	Compatibility string `yaml:"compatibility"`
 }
 
 // HTTPBasicAuth for authentication via HTTP.
@@ -15,8 +50,25 @@ type HTTPBasicAuth struct {
 
 // Validate SchemaRegistry config.
 func (c *SchemaRegistry) Validate() error {
-	if c.Address == "" {
-		return nil
+	switch c.SubjectNameStrategy {
+	case SubjectNameStrategyTopicName, SubjectNameStrategyRecordName, SubjectNameStrategyTopicRecordName:
+	default:
+		return fmt.Errorf("invalid subject name strategy '%v', valid strategies are: %v, %v, %v", c.SubjectNameStrategy,
+			SubjectNameStrategyTopicName, SubjectNameStrategyRecordName, SubjectNameStrategyTopicRecordName)
+	}
+
+	if c.Compatibility != "" {
+		valid := false
+		for _, level := range compatibilityLevels {
+			if c.Compatibility == level {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("invalid compatibility '%v', valid levels are: %v",
+				c.Compatibility, strings.Join(compatibilityLevels, ", "))
+		}
 	}
 
 	return nil
@@ -5,6 +5,15 @@ import (
 	"time"
 )
 
+const (
+	// ReconcileModeCreateOnly only creates missing topics, existing topics are not compared.
+	ReconcileModeCreateOnly = "createOnly"
+	// ReconcileModeWarn logs differences between existing topics and the config.
+	ReconcileModeWarn = "warn"
+	// ReconcileModeEnforce adds missing partitions and alters differing topic configs.
+	ReconcileModeEnforce = "enforce"
+)
+
 // Shop is the configuration for the virtual shop that emits Kafka records
 // upon simulated page impressions.
 type Shop struct {
@@ -17,6 +26,40 @@ type Shop struct {
 	// shall be performed. Defaults to 1s.
 	RequestRateInterval time.Duration `yaml:"interval"`
 
+	// RunFor is the duration after which the shop stops generating events
+	// and shuts down. Defaults to 0, which runs the shop until it is stopped.
+	RunFor time.Duration `yaml:"runFor"`
+
+	// MaxEvents bounds the number of generated events. Once the bound is
+	// reached the shop shuts down.
+	MaxEvents ShopMaxEvents `yaml:"maxEvents"`
+
+	// Seed for all randomly generated data. Two runs with the same seed
+	// produce the same sequence of records. Timestamps of the generated data
+	// and their records are derived from a simulated clock in that case. A
+	// seed requires a single worker, because concurrent workers reorder the
+	// generated data. Avro encoded maps are the only exception, their entries
+	// are written in random order. Defaults to 0, which uses a random seed.
+	Seed int64 `yaml:"seed"`
+
+	// Workers is the number of workers that concurrently simulate page
+	// impressions. Defaults to 16.
+	Workers int `yaml:"workers"`
+
+	// MaxInFlight is the maximum number of page impressions that may be
+	// queued or executed at the same time. Page impressions that are
+	// scheduled while this limit is reached are dropped. Defaults to 1000.
+	MaxInFlight int `yaml:"maxInFlight"`
+
+	// Traffic is the traffic profile that modulates the request rate over
+	// time. By default, the request rate is constant.
+	Traffic ShopTraffic `yaml:"traffic"`
+
+	// DrainTimeout is the maximum duration to wait for in-flight page
+	// impressions, buffered records and consumer offset commits when the
+	// shop is being stopped. Defaults to 30s.
+	DrainTimeout time.Duration `yaml:"drainTimeout"`
+
 	// Prefix for all topic names, consumer group names, client ids etc.
 	GlobalPrefix string `yaml:"globalPrefix"`
 
@@ -26,6 +69,37 @@ type Shop struct {
 	// TopicPartitionCount that shall be used for all Kafka topics.
 	TopicPartitionCount int32 `yaml:"topicPartitionCount"`
 
+	// Topics overrides the name, partition count, replication factor and
+	// topic configs of individual topics, keyed by the logical topic name
+	// (e.g. frontendEvents).
+	Topics ShopTopics `yaml:"topics"`
+
+	// TransactionalOrders produces each order atomically to all order topics
+	// using a transactional producer. The consumed customer offsets of the
+	// order service are committed within the same transactions. Requires the
+	// kafka sink and an idempotent producer.
+	TransactionalOrders bool `yaml:"transactionalOrders"`
+
+	// ReconcileMode determines how differences between existing topics and
+	// the configured partition count and topic configs are handled. Valid
+	// values are createOnly, warn and enforce. Defaults to warn.
+	ReconcileMode string `yaml:"reconcileMode"`
+
+	// Keys configures the format of the record keys.
+	Keys ShopKeys `yaml:"keys"`
+
+	// SchemaEvolution is the timeline of customer schema versions that are
+	// registered while the shop is running.
+	SchemaEvolution ShopSchemaEvolution `yaml:"schemaEvolution"`
+
+	// EventMix configures how likely each action (e.g. creating an order)
+	// is triggered by a simulated page impression.
+	EventMix ShopEventMix `yaml:"eventMix"`
+
+	// Payments configures how likely the payment of an order is declined,
+	// depending on the payment method.
+	Payments ShopPayments `yaml:"payments"`
+
 	// Meta is the config for the meta service that creates additional
 	// resources such as ACLs that are not required for generating
 	// data, but may help to create a more production-like environment.
@@ -37,8 +111,16 @@ func (c *Shop) SetDefaults() {
 	c.GlobalPrefix = "owlshop-"
 	c.RequestRate = 2
 	c.RequestRateInterval = time.Second
+	c.Workers = 16
+	c.MaxInFlight = 1000
+	c.DrainTimeout = 30 * time.Second
+	c.Traffic.SetDefaults()
 	c.TopicReplicationFactor = -1
 	c.TopicPartitionCount = 1
+	c.ReconcileMode = ReconcileModeWarn
+	c.Keys.SetDefaults()
+	c.EventMix.SetDefaults()
+	c.Payments.SetDefaults()
 	c.Meta.Enabled = true
 }
 
@@ -52,6 +134,34 @@ func (c *Shop) Validate() error {
 		return fmt.Errorf("request rate must be a valid duration (e.g. '1s')")
 	}
 
+	if c.RunFor < 0 {
+		return fmt.Errorf("run for must not be a negative duration")
+	}
+
+	if err := c.MaxEvents.Validate(c.EventMix); err != nil {
+		return fmt.Errorf("failed to validate max events: %w", err)
+	}
+
+	if c.Workers <= 0 {
+		return fmt.Errorf("workers must be a positive integer")
+	}
+
+	if c.Seed != 0 && c.Workers != 1 {
+		return fmt.Errorf("a seed requires a single worker to produce reproducible records, set workers to 1")
+	}
+
+	if c.MaxInFlight < c.Workers {
+		return fmt.Errorf("max in-flight must be greater than or equal to the number of workers")
+	}
+
+	if err := c.Traffic.Validate(); err != nil {
+		return fmt.Errorf("failed to validate traffic profile: %w", err)
+	}
+
+	if c.DrainTimeout <= 0 {
+		return fmt.Errorf("drain timeout must be a positive duration (e.g. '30s')")
+	}
+
 	if c.TopicReplicationFactor < -1 || c.TopicReplicationFactor == 0 {
 		return fmt.Errorf("replication factor must be a positive integer or '-1' for using default replication factor")
 	}
@@ -60,5 +170,40 @@ func (c *Shop) Validate() error {
 		return fmt.Errorf("partition count must be a positive integer or '-1' for using the default partition count")
 	}
 
+	if err := c.Topics.Validate(); err != nil {
+		return fmt.Errorf("failed to validate topics: %w", err)
+	}
+	topicKeys := make(map[string]string)
+	for _, key := range ShopTopicKeys() {
+		name := c.Topic(key).Name
+		if otherKey, exists := topicKeys[name]; exists {
+			return fmt.Errorf("topics '%v' and '%v' must not have the same name '%v'", otherKey, key, name)
+		}
+		topicKeys[name] = key
+	}
+
+	switch c.ReconcileMode {
+	case ReconcileModeCreateOnly, ReconcileModeWarn, ReconcileModeEnforce:
+	default:
+		return fmt.Errorf("reconcile mode '%v' is invalid, valid values are: %v, %v, %v",
+			c.ReconcileMode, ReconcileModeCreateOnly, ReconcileModeWarn, ReconcileModeEnforce)
+	}
+
+	if err := c.Keys.Validate(); err != nil {
+		return fmt.Errorf("failed to validate keys: %w", err)
+	}
+
+	if err := c.SchemaEvolution.Validate(); err != nil {
+		return fmt.Errorf("failed to validate schema evolution: %w", err)
+	}
+
+	if err := c.EventMix.Validate(); err != nil {
+		return fmt.Errorf("failed to validate event mix: %w", err)
+	}
+
+	if err := c.Payments.Validate(); err != nil {
+		return fmt.Errorf("failed to validate payments: %w", err)
+	}
+
 	return nil
 }
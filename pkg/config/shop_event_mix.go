@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ShopActionFrontendEvent  = "frontendEvent"
+	ShopActionCreateCustomer = "createCustomer"
+	ShopActionModifyCustomer = "modifyCustomer"
+	ShopActionDeleteCustomer = "deleteCustomer"
+	ShopActionCreateAddress  = "createAddress"
+	ShopActionCreateOrder    = "createOrder"
+	ShopActionUpdateOrder    = "updateOrder"
+	ShopActionScanShipment   = "scanShipment"
+)
+
+// ShopActions returns the names of all actions that can be triggered by a
+// simulated page impression. The order is stable.
+func ShopActions() []string {
+	return []string{
+		ShopActionFrontendEvent,
+		ShopActionCreateCustomer,
+		ShopActionModifyCustomer,
+		ShopActionDeleteCustomer,
+		ShopActionCreateAddress,
+		ShopActionCreateOrder,
+		ShopActionUpdateOrder,
+		ShopActionScanShipment,
+	}
+}
+
+// ShopEventMix maps action names to their relative weight. Each simulated
+// page impression randomly picks one action based on these weights. An
+// action can be disabled by setting its weight to 0. Actions that are not
+// specified keep their default weight.
+type ShopEventMix map[string]uint
+
+// SetDefaults for the event mix.
+func (c *ShopEventMix) SetDefaults() {
+	*c = ShopEventMix{
+		ShopActionFrontendEvent:  1000,
+		ShopActionCreateCustomer: 50,
+		ShopActionCreateAddress:  30,
+		ShopActionDeleteCustomer: 8,
+		ShopActionModifyCustomer: 6,
+		ShopActionCreateOrder:    5,
+		ShopActionUpdateOrder:    15,
+		ShopActionScanShipment:   20,
+	}
+}
+
+// Normalized returns a copy of the event mix with canonical action names, see
+// normalizeActions.
+func (c ShopEventMix) Normalized() ShopEventMix {
+	return normalizeActions(c)
+}
+
+// normalizeActions returns a copy of the given map with canonical action names,
+// see normalizeNames.
+func normalizeActions[M ~map[string]V, V any](actions M) M {
+	return normalizeNames(actions, ShopActions())
+}
+
+// normalizeNames returns a copy of the given map in which all keys are matched
+// case-insensitively against the canonical names and replaced by them, because
+// environment variables don't preserve the case of the keys. Values of
+// differently cased keys take precedence over the value of the canonical key,
+// because they have been set by a later config source. Unknown keys are kept
+// as they are, so that they are reported by the validation.
+func normalizeNames[M ~map[string]V, V any](values M, canonicalNames []string) M {
+	if values == nil {
+		return nil
+	}
+
+	canonicalByLower := make(map[string]string, len(canonicalNames))
+	for _, name := range canonicalNames {
+		canonicalByLower[strings.ToLower(name)] = name
+	}
+
+	normalized := make(M, len(values))
+	for name, value := range values {
+		canonical, exists := canonicalByLower[strings.ToLower(name)]
+		if !exists {
+			normalized[name] = value
+			continue
+		}
+		if _, isSet := normalized[canonical]; isSet && name == canonical {
+			continue
+		}
+		normalized[canonical] = value
+	}
+
+	return normalized
+}
+
+// Validate the event mix.
+func (c ShopEventMix) Validate() error {
+	knownActions := make(map[string]struct{})
+	for _, action := range ShopActions() {
+		knownActions[action] = struct{}{}
+	}
+
+	var totalWeight uint
+	for action, weight := range c {
+		if _, exists := knownActions[action]; !exists {
+			return fmt.Errorf("unknown action '%v', valid actions are: %v", action, strings.Join(ShopActions(), ", "))
+		}
+		totalWeight += weight
+	}
+
+	if totalWeight == 0 {
+		return fmt.Errorf("at least one action must have a weight greater than 0")
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShopEventMixValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		eventMix ShopEventMix
+		wantErr  bool
+	}{
+		{name: "defaults", eventMix: defaultEventMix()},
+		{name: "single action", eventMix: ShopEventMix{ShopActionCreateOrder: 1}},
+		{name: "unknown action", eventMix: ShopEventMix{"createInvoice": 1}, wantErr: true},
+		{name: "all disabled", eventMix: ShopEventMix{ShopActionCreateOrder: 0}, wantErr: true},
+		{name: "empty", eventMix: ShopEventMix{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.eventMix.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShopEventMixNormalized(t *testing.T) {
+	tests := []struct {
+		name     string
+		eventMix ShopEventMix
+		want     ShopEventMix
+	}{
+		{
+			name:     "canonical names are kept",
+			eventMix: ShopEventMix{ShopActionCreateOrder: 5, ShopActionUpdateOrder: 15},
+			want:     ShopEventMix{ShopActionCreateOrder: 5, ShopActionUpdateOrder: 15},
+		},
+		{
+			name:     "lowercased names are renamed",
+			eventMix: ShopEventMix{"createorder": 5, "UPDATEORDER": 15},
+			want:     ShopEventMix{ShopActionCreateOrder: 5, ShopActionUpdateOrder: 15},
+		},
+		{
+			name:     "differently cased names take precedence",
+			eventMix: ShopEventMix{ShopActionCreateOrder: 5, "createorder": 1},
+			want:     ShopEventMix{ShopActionCreateOrder: 1},
+		},
+		{
+			name:     "unknown actions are kept",
+			eventMix: ShopEventMix{"createinvoice": 1},
+			want:     ShopEventMix{"createinvoice": 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.eventMix.Normalized(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Normalized() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func defaultEventMix() ShopEventMix {
+	var eventMix ShopEventMix
+	eventMix.SetDefaults()
+	return eventMix
+}
@@ -0,0 +1,53 @@
+package config
+
+import "fmt"
+
+const (
+	// KeyFormatString uses the entity's id as UTF-8 string key.
+	KeyFormatString = "string"
+	// KeyFormatJSON uses a JSON encoded key with the entity's id and tenant.
+	KeyFormatJSON = "json"
+	// KeyFormatAvroSR uses an Avro key with the entity's id and tenant that is
+	// encoded with the schema registry wire format.
+	KeyFormatAvroSR = "avroSr"
+	// KeyFormatProtobufSR uses a protobuf key with the entity's id and tenant
+	// that is encoded with the schema registry wire format.
+	KeyFormatProtobufSR = "protobufSr"
+)
+
+// ShopKeys configures the record keys of all entity topics. Frontend events
+// are produced without keys regardless of the key format.
+type ShopKeys struct {
+	// Format of the record keys. Valid values are string, json, avroSr and
+	// protobufSr. The key schemas of the schema registry formats are
+	// registered under the subject '<topic>-key', unless a different subject
+	// name strategy is configured. Defaults to string.
+	Format string `yaml:"format"`
+
+	// Tenant is set in all structured keys. Defaults to 'owlshop'.
+	Tenant string `yaml:"tenant"`
+}
+
+// SetDefaults for the keys config.
+func (c *ShopKeys) SetDefaults() {
+	c.Format = KeyFormatString
+	c.Tenant = "owlshop"
+}
+
+// Validate the keys config.
+func (c *ShopKeys) Validate() error {
+	switch c.Format {
+	case KeyFormatString, KeyFormatJSON, KeyFormatAvroSR, KeyFormatProtobufSR:
+	default:
+		return fmt.Errorf("key format '%v' is invalid, valid values are: %v, %v, %v, %v",
+			c.Format, KeyFormatString, KeyFormatJSON, KeyFormatAvroSR, KeyFormatProtobufSR)
+	}
+
+	return nil
+}
+
+// IsSchemaRegistryFormat returns true if the keys are encoded with the schema
+// registry wire format.
+func (c *ShopKeys) IsSchemaRegistryFormat() bool {
+	return c.Format == KeyFormatAvroSR || c.Format == KeyFormatProtobufSR
+}
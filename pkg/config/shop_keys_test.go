@@ -0,0 +1,29 @@
+package config
+
+import "testing"
+
+func TestShopKeysValidate(t *testing.T) {
+	tests := []struct {
+		format                   string
+		wantErr                  bool
+		wantSchemaRegistryFormat bool
+	}{
+		{format: KeyFormatString},
+		{format: KeyFormatJSON},
+		{format: KeyFormatAvroSR, wantSchemaRegistryFormat: true},
+		{format: KeyFormatProtobufSR, wantSchemaRegistryFormat: true},
+		{format: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			cfg := ShopKeys{Format: tt.format, Tenant: "owlshop"}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := cfg.IsSchemaRegistryFormat(); got != tt.wantSchemaRegistryFormat {
+				t.Errorf("IsSchemaRegistryFormat() = %v, want %v", got, tt.wantSchemaRegistryFormat)
+			}
+		})
+	}
+}
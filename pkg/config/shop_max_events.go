@@ -0,0 +1,39 @@
+package config
+
+import (
+	"fmt"
+)
+
+// ShopMaxEvents bounds the number of events the shop generates before it
+// stops. Only actions whose records were handed to the sink are counted, e.g.
+// an order is not counted if there was no customer the order could be created
+// for. Records are produced asynchronously, so that records whose produce
+// fails later on are counted nonetheless.
+type ShopMaxEvents struct {
+	// Total is the maximum number of events across all actions. Defaults to
+	// 0, which means unbounded.
+	Total uint64 `yaml:"total"`
+
+	// Actions maps action names to the maximum number of events for that
+	// action. Once an action has reached its maximum it is no longer picked.
+	// The shop stops once all actions listed here have reached their maximum.
+	Actions map[string]uint64 `yaml:"actions"`
+}
+
+// Validate the max events config.
+func (c *ShopMaxEvents) Validate(eventMix ShopEventMix) error {
+	for action, maxEvents := range c.Actions {
+		weight, exists := eventMix[action]
+		if !exists {
+			return fmt.Errorf("unknown action '%v'", action)
+		}
+		if maxEvents == 0 {
+			return fmt.Errorf("max events for action '%v' must be greater than 0", action)
+		}
+		if weight == 0 {
+			return fmt.Errorf("max events for action '%v' can never be reached, because it is disabled in the event mix", action)
+		}
+	}
+
+	return nil
+}
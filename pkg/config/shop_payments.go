@@ -0,0 +1,69 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	PaymentMethodCash       = "CASH"
+	PaymentMethodDebit      = "DEBIT"
+	PaymentMethodCreditCard = "CREDIT_CARD"
+	PaymentMethodPaypal     = "PAYPAL"
+)
+
+// PaymentMethods returns all payment methods that orders are paid with. The
+// order is stable.
+func PaymentMethods() []string {
+	return []string{
+		PaymentMethodCash,
+		PaymentMethodDebit,
+		PaymentMethodCreditCard,
+		PaymentMethodPaypal,
+	}
+}
+
+// ShopPayments configures the payment service that processes the payment of
+// each order.
+type ShopPayments struct {
+	// FailureRates maps payment methods to the probability (between 0 and 1)
+	// that the authorization of a payment is declined. Methods that are not
+	// specified keep their default failure rate.
+	FailureRates map[string]float64 `yaml:"failureRates"`
+}
+
+// SetDefaults for the payments config.
+func (c *ShopPayments) SetDefaults() {
+	c.FailureRates = map[string]float64{
+		PaymentMethodCash:       0,
+		PaymentMethodDebit:      0.05,
+		PaymentMethodCreditCard: 0.08,
+		PaymentMethodPaypal:     0.03,
+	}
+}
+
+// Normalized returns a copy of the payments config with canonical payment
+// method names, see normalizeNames.
+func (c ShopPayments) Normalized() ShopPayments {
+	c.FailureRates = normalizeNames(c.FailureRates, PaymentMethods())
+	return c
+}
+
+// Validate the payments config.
+func (c *ShopPayments) Validate() error {
+	knownMethods := make(map[string]struct{})
+	for _, method := range PaymentMethods() {
+		knownMethods[method] = struct{}{}
+	}
+
+	for method, rate := range c.FailureRates {
+		if _, exists := knownMethods[method]; !exists {
+			return fmt.Errorf("unknown payment method '%v', valid methods are: %v", method, strings.Join(PaymentMethods(), ", "))
+		}
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("failure rate of payment method '%v' must be between 0 and 1", method)
+		}
+	}
+
+	return nil
+}
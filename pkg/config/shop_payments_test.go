@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestShopPaymentsValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		failureRates map[string]float64
+		wantErr      bool
+	}{
+		{name: "no failure rates"},
+		{name: "known method", failureRates: map[string]float64{PaymentMethodCreditCard: 0.5}},
+		{name: "unknown method", failureRates: map[string]float64{"BITCOIN": 0.5}, wantErr: true},
+		{name: "rate above 1", failureRates: map[string]float64{PaymentMethodCash: 1.5}, wantErr: true},
+		{name: "negative rate", failureRates: map[string]float64{PaymentMethodCash: -0.1}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := ShopPayments{FailureRates: tt.failureRates}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadConfigNormalizesPaymentMethods(t *testing.T) {
+	t.Setenv("CONFIG_FILEPATH", "")
+
+	cfg, err := LoadConfig(zap.NewNop(), "", map[string]string{
+		"shop.payments.failureRates.credit_card": "0.5",
+	})
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if err := cfg.Shop.Payments.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if got := cfg.Shop.Payments.FailureRates[PaymentMethodCreditCard]; got != 0.5 {
+		t.Errorf("failure rate of %v = %v, want 0.5", PaymentMethodCreditCard, got)
+	}
+	if got := cfg.Shop.Payments.FailureRates[PaymentMethodDebit]; got != 0.05 {
+		t.Errorf("failure rate of %v = %v, want the default 0.05", PaymentMethodDebit, got)
+	}
+}
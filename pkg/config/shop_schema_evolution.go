@@ -0,0 +1,59 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxSchemaEvolutionSteps is the number of customer schema versions that can be
+// registered by the schema evolution.
+const MaxSchemaEvolutionSteps = 3
+
+// ShopSchemaEvolution is a timeline of customer schema versions. Each step
+// registers the next compatible version of the customer schemas on the schema
+// registry encoded customer topics and starts producing records with it:
+//
+//  1. v3 adds the optional field loyaltyTier
+//  2. v4 renames email to emailAddress (with an alias in Avro)
+//  3. v5 adds the customer type GOVERNMENT
+type ShopSchemaEvolution struct {
+	// Steps are applied in order, a step is only due once its previous step
+	// has been applied. At most MaxSchemaEvolutionSteps steps can be configured.
+	Steps []ShopSchemaEvolutionStep `yaml:"steps"`
+
+	// LaggingProducerRatio is the share of customer records that are still
+	// produced with the previous schema version, as if they were produced by
+	// producers that haven't been upgraded yet. Defaults to 0.
+	LaggingProducerRatio float64 `yaml:"laggingProducerRatio"`
+}
+
+// ShopSchemaEvolutionStep is due once either bound has been reached.
+type ShopSchemaEvolutionStep struct {
+	// After is the duration since the shop has been started.
+	After time.Duration `yaml:"after"`
+
+	// AfterCustomerEvents is the number of customers that have been created or
+	// modified since the shop has been started.
+	AfterCustomerEvents uint64 `yaml:"afterCustomerEvents"`
+}
+
+// Validate the schema evolution config.
+func (c *ShopSchemaEvolution) Validate() error {
+	if len(c.Steps) > MaxSchemaEvolutionSteps {
+		return fmt.Errorf("at most %d steps can be configured", MaxSchemaEvolutionSteps)
+	}
+	for i, step := range c.Steps {
+		if step.After < 0 {
+			return fmt.Errorf("after of step %d must not be a negative duration", i+1)
+		}
+		if step.After == 0 && step.AfterCustomerEvents == 0 {
+			return fmt.Errorf("step %d must either set after or afterCustomerEvents", i+1)
+		}
+	}
+
+	if c.LaggingProducerRatio < 0 || c.LaggingProducerRatio > 1 {
+		return fmt.Errorf("lagging producer ratio must be between 0 and 1")
+	}
+
+	return nil
+}
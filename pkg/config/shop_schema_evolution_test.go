@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShopSchemaEvolutionValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		evolution ShopSchemaEvolution
+		wantErr   bool
+	}{
+		{name: "no steps"},
+		{
+			name: "all steps",
+			evolution: ShopSchemaEvolution{
+				Steps: []ShopSchemaEvolutionStep{
+					{After: time.Minute},
+					{AfterCustomerEvents: 100},
+					{After: time.Hour, AfterCustomerEvents: 1000},
+				},
+				LaggingProducerRatio: 0.2,
+			},
+		},
+		{
+			name: "too many steps",
+			evolution: ShopSchemaEvolution{Steps: []ShopSchemaEvolutionStep{
+				{After: time.Minute}, {After: time.Minute}, {After: time.Minute}, {After: time.Minute},
+			}},
+			wantErr: true,
+		},
+		{
+			name:      "negative after",
+			evolution: ShopSchemaEvolution{Steps: []ShopSchemaEvolutionStep{{After: -time.Minute}}},
+			wantErr:   true,
+		},
+		{
+			name:      "step without bound",
+			evolution: ShopSchemaEvolution{Steps: []ShopSchemaEvolutionStep{{}}},
+			wantErr:   true,
+		},
+		{
+			name:      "lagging producer ratio above 1",
+			evolution: ShopSchemaEvolution{LaggingProducerRatio: 1.5},
+			wantErr:   true,
+		},
+		{
+			name:      "negative lagging producer ratio",
+			evolution: ShopSchemaEvolution{LaggingProducerRatio: -0.1},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.evolution.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,28 @@
+package config
+
+import "testing"
+
+func TestShopValidateSeed(t *testing.T) {
+	tests := []struct {
+		name    string
+		seed    int64
+		workers int
+		wantErr bool
+	}{
+		{name: "random seed with many workers", seed: 0, workers: 16},
+		{name: "seed with single worker", seed: 42, workers: 1},
+		{name: "seed with many workers", seed: 42, workers: 16, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg Shop
+			cfg.SetDefaults()
+			cfg.Seed = tt.seed
+			cfg.Workers = tt.workers
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ShopTopicCustomers                = "customers"
+	ShopTopicCustomersProtobufSr      = "customersProtobufSr"
+	ShopTopicCustomersAvroSr          = "customersAvroSr"
+	ShopTopicCustomersJsonSr          = "customersJsonSr"
+	ShopTopicAddresses                = "addresses"
+	ShopTopicAddressesProtobufSr      = "addressesProtobufSr"
+	ShopTopicAddressesAvroSr          = "addressesAvroSr"
+	ShopTopicAddressesJsonSr          = "addressesJsonSr"
+	ShopTopicFrontendEvents           = "frontendEvents"
+	ShopTopicFrontendEventsProtobufSr = "frontendEventsProtobufSr"
+	ShopTopicFrontendEventsAvroSr     = "frontendEventsAvroSr"
+	ShopTopicFrontendEventsJsonSr     = "frontendEventsJsonSr"
+	ShopTopicOrders                   = "orders"
+	ShopTopicOrdersProtobufPlain      = "ordersProtobufPlain"
+	ShopTopicOrdersProtobufSr         = "ordersProtobufSr"
+	ShopTopicOrdersAvroSr             = "ordersAvroSr"
+	ShopTopicOrdersJsonSr             = "ordersJsonSr"
+	ShopTopicShipments                = "shipments"
+	ShopTopicPayments                 = "payments"
+)
+
+// shopTopicDefaultNames maps the logical topics to their default names
+// without the global prefix.
+var shopTopicDefaultNames = map[string]string{
+	ShopTopicCustomers:                "customers",
+	ShopTopicCustomersProtobufSr:      "customers-protobuf-sr",
+	ShopTopicCustomersAvroSr:          "customers-avro-sr",
+	ShopTopicCustomersJsonSr:          "customers-json-sr",
+	ShopTopicAddresses:                "addresses",
+	ShopTopicAddressesProtobufSr:      "addresses-protobuf-sr",
+	ShopTopicAddressesAvroSr:          "addresses-avro-sr",
+	ShopTopicAddressesJsonSr:          "addresses-json-sr",
+	ShopTopicFrontendEvents:           "frontend-events",
+	ShopTopicFrontendEventsProtobufSr: "frontend-events-protobuf-sr",
+	ShopTopicFrontendEventsAvroSr:     "frontend-events-avro-sr",
+	ShopTopicFrontendEventsJsonSr:     "frontend-events-json-sr",
+	ShopTopicOrders:                   "orders",
+	ShopTopicOrdersProtobufPlain:      "orders-protobuf-plain",
+	ShopTopicOrdersProtobufSr:         "orders-protobuf-sr",
+	ShopTopicOrdersAvroSr:             "orders-avro-sr",
+	ShopTopicOrdersJsonSr:             "orders-json-sr",
+	ShopTopicShipments:                "shipments",
+	ShopTopicPayments:                 "payments",
+}
+
+// ShopTopicKeys returns the keys of all logical topics the shop produces
+// to. The order is stable.
+func ShopTopicKeys() []string {
+	return []string{
+		ShopTopicCustomers,
+		ShopTopicCustomersProtobufSr,
+		ShopTopicCustomersAvroSr,
+		ShopTopicCustomersJsonSr,
+		ShopTopicAddresses,
+		ShopTopicAddressesProtobufSr,
+		ShopTopicAddressesAvroSr,
+		ShopTopicAddressesJsonSr,
+		ShopTopicFrontendEvents,
+		ShopTopicFrontendEventsProtobufSr,
+		ShopTopicFrontendEventsAvroSr,
+		ShopTopicFrontendEventsJsonSr,
+		ShopTopicOrders,
+		ShopTopicOrdersProtobufPlain,
+		ShopTopicOrdersProtobufSr,
+		ShopTopicOrdersAvroSr,
+		ShopTopicOrdersJsonSr,
+		ShopTopicShipments,
+		ShopTopicPayments,
+	}
+}
+
+// ShopTopic overrides the settings of a single topic. Unset values fall back
+// to the shop-wide topic settings.
+type ShopTopic struct {
+	// Name of the topic. The global prefix is prepended, so that the topic
+	// is still scoped to the shop. Defaults to the name derived from the key,
+	// e.g. 'frontend-events' for frontendEvents.
+	Name string `yaml:"name"`
+
+	// Partitions of the topic, -1 uses the broker's default. Defaults to
+	// the shop's topicPartitionCount.
+	Partitions int32 `yaml:"partitions"`
+
+	// ReplicationFactor of the topic, -1 uses the broker's default.
+	// Defaults to the shop's topicReplicationFactor.
+	ReplicationFactor int16 `yaml:"replicationFactor"`
+
+	// Configs are topic configs (e.g. retention.ms) that are set in addition
+	// to, or instead of, the shop's default configs for this topic.
+	Configs map[string]string `yaml:"configs"`
+}
+
+// ShopTopics maps logical topic keys to the overrides for that topic.
+type ShopTopics map[string]ShopTopic
+
+// Validate the topic overrides.
+func (c ShopTopics) Validate() error {
+	for key, topic := range c {
+		if _, exists := shopTopicDefaultNames[key]; !exists {
+			return fmt.Errorf("unknown topic '%v', valid topics are: %v", key, strings.Join(ShopTopicKeys(), ", "))
+		}
+		if topic.Partitions < -1 {
+			return fmt.Errorf("partition count of topic '%v' must be a positive integer or '-1' for using the default partition count", key)
+		}
+		if topic.ReplicationFactor < -1 {
+			return fmt.Errorf("replication factor of topic '%v' must be a positive integer or '-1' for using the default replication factor", key)
+		}
+	}
+
+	return nil
+}
+
+// Topic returns the effective settings of the given logical topic, including
+// the global prefix in its name. Configs only contain the configured overrides.
+func (c Shop) Topic(key string) ShopTopic {
+	topic := c.Topics[key]
+
+	if topic.Name == "" {
+		topic.Name = shopTopicDefaultNames[key]
+	}
+	topic.Name = c.GlobalPrefix + topic.Name
+	if topic.Partitions == 0 {
+		topic.Partitions = c.TopicPartitionCount
+	}
+	if topic.ReplicationFactor == 0 {
+		topic.ReplicationFactor = c.TopicReplicationFactor
+	}
+
+	return topic
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShopTopicsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		topics  ShopTopics
+		wantErr bool
+	}{
+		{name: "no overrides"},
+		{name: "overrides", topics: ShopTopics{ShopTopicOrders: {Name: "orders-v2", Partitions: 6, ReplicationFactor: 3}}},
+		{name: "broker defaults", topics: ShopTopics{ShopTopicOrders: {Partitions: -1, ReplicationFactor: -1}}},
+		{name: "unknown topic", topics: ShopTopics{"invoices": {}}, wantErr: true},
+		{name: "invalid partitions", topics: ShopTopics{ShopTopicOrders: {Partitions: -2}}, wantErr: true},
+		{name: "invalid replication factor", topics: ShopTopics{ShopTopicOrders: {ReplicationFactor: -2}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.topics.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShopTopic(t *testing.T) {
+	var cfg Shop
+	cfg.SetDefaults()
+	cfg.TopicPartitionCount = 3
+	cfg.TopicReplicationFactor = 2
+	cfg.Topics = ShopTopics{
+		ShopTopicOrders: {Name: "orders-v2", Partitions: 6, Configs: map[string]string{"retention.ms": "1000"}},
+	}
+
+	tests := []struct {
+		key  string
+		want ShopTopic
+	}{
+		{
+			key:  ShopTopicFrontendEvents,
+			want: ShopTopic{Name: "owlshop-frontend-events", Partitions: 3, ReplicationFactor: 2},
+		},
+		{
+			key: ShopTopicOrders,
+			want: ShopTopic{
+				Name:              "owlshop-orders-v2",
+				Partitions:        6,
+				ReplicationFactor: 2,
+				Configs:           map[string]string{"retention.ms": "1000"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if got := cfg.Topic(tt.key); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Topic() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
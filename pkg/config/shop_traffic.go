@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// ShopTraffic configures a traffic profile that modulates the configured
+// request rate over time. All enabled modulations are multiplied with each
+// other and with the request rate, so that a flash sale burst during the
+// daily peak results in more traffic than the same burst at night.
+type ShopTraffic struct {
+	// Diurnal simulates the daily traffic curve of a shop with a peak during
+	// the day and a trough at night.
+	Diurnal ShopTrafficDiurnal `yaml:"diurnal"`
+
+	// Ramps linearly change the traffic from one factor to another, relative
+	// to the time the shop has been started. This can be used to slowly ramp
+	// up traffic after startup or to ramp it down after some time.
+	Ramps []ShopTrafficRamp `yaml:"ramps"`
+
+	// Bursts are periodic traffic increases, such as flash sales.
+	Bursts []ShopTrafficBurst `yaml:"bursts"`
+
+	// Spikes are traffic increases that start at random times.
+	Spikes ShopTrafficSpikes `yaml:"spikes"`
+}
+
+// ShopTrafficDiurnal configures a cosine shaped 24h traffic curve.
+type ShopTrafficDiurnal struct {
+	Enabled bool `yaml:"enabled"`
+
+	// PeakHour is the hour of the day (0-23, local time) with the highest
+	// traffic. Defaults to 18.
+	PeakHour int `yaml:"peakHour"`
+
+	// TroughFactor is the factor applied to the request rate at the time of
+	// the day with the lowest traffic (12h after the peak hour). At the peak
+	// hour the factor is always 1. Defaults to 0.2.
+	TroughFactor float64 `yaml:"troughFactor"`
+}
+
+// ShopTrafficRamp linearly changes the traffic factor from {From} to {To}
+// within {Duration}, starting {After} the shop has been started. Once the ramp
+// has finished the traffic factor remains at {To} until the next ramp starts.
+type ShopTrafficRamp struct {
+	After    time.Duration `yaml:"after"`
+	Duration time.Duration `yaml:"duration"`
+	From     float64       `yaml:"from"`
+	To       float64       `yaml:"to"`
+}
+
+// ShopTrafficBurst multiplies the traffic by {Factor} for {Duration} every
+// {Every}. The first burst starts {Offset} after the shop has been started.
+type ShopTrafficBurst struct {
+	Every    time.Duration `yaml:"every"`
+	Duration time.Duration `yaml:"duration"`
+	Offset   time.Duration `yaml:"offset"`
+	Factor   float64       `yaml:"factor"`
+}
+
+// ShopTrafficSpikes randomly multiplies the traffic by {Factor} for {Duration}.
+// On average one spike starts every {AverageInterval}.
+type ShopTrafficSpikes struct {
+	Enabled         bool          `yaml:"enabled"`
+	AverageInterval time.Duration `yaml:"averageInterval"`
+	Duration        time.Duration `yaml:"duration"`
+	Factor          float64       `yaml:"factor"`
+}
+
+// SetDefaults for the traffic profile.
+func (c *ShopTraffic) SetDefaults() {
+	c.Diurnal.PeakHour = 18
+	c.Diurnal.TroughFactor = 0.2
+
+	c.Spikes.AverageInterval = time.Hour
+	c.Spikes.Duration = time.Minute
+	c.Spikes.Factor = 5
+}
+
+// Validate the traffic profile.
+func (c *ShopTraffic) Validate() error {
+	if c.Diurnal.PeakHour < 0 || c.Diurnal.PeakHour > 23 {
+		return fmt.Errorf("diurnal peak hour must be between 0 and 23")
+	}
+	if c.Diurnal.TroughFactor < 0 || c.Diurnal.TroughFactor > 1 {
+		return fmt.Errorf("diurnal trough factor must be between 0 and 1")
+	}
+
+	for i, ramp := range c.Ramps {
+		if ramp.After < 0 || ramp.Duration < 0 {
+			return fmt.Errorf("ramp at index %d: after and duration must not be negative", i)
+		}
+		if ramp.From < 0 || ramp.To < 0 {
+			return fmt.Errorf("ramp at index %d: factors must not be negative", i)
+		}
+		if i > 0 && ramp.After < c.Ramps[i-1].After+c.Ramps[i-1].Duration {
+			return fmt.Errorf("ramp at index %d: ramps must be ordered and must not overlap", i)
+		}
+	}
+
+	for i, burst := range c.Bursts {
+		if burst.Every <= 0 || burst.Duration <= 0 {
+			return fmt.Errorf("burst at index %d: every and duration must be positive durations", i)
+		}
+		if burst.Duration > burst.Every {
+			return fmt.Errorf("burst at index %d: duration must not be longer than the burst interval", i)
+		}
+		if burst.Offset < 0 {
+			return fmt.Errorf("burst at index %d: offset must not be negative", i)
+		}
+		if burst.Factor < 0 {
+			return fmt.Errorf("burst at index %d: factor must not be negative", i)
+		}
+	}
+
+	if c.Spikes.Enabled {
+		if c.Spikes.AverageInterval <= 0 || c.Spikes.Duration <= 0 {
+			return fmt.Errorf("spikes: average interval and duration must be positive durations")
+		}
+		if c.Spikes.Factor < 0 {
+			return fmt.Errorf("spikes: factor must not be negative")
+		}
+	}
+
+	return nil
+}
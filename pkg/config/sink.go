@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+)
+
+const (
+	SinkTypeKafka  = "kafka"
+	SinkTypeFile   = "file"
+	SinkTypeStdout = "stdout"
+
+	SinkStdoutFormatText = "text"
+	SinkStdoutFormatJSON = "json"
+)
+
+// Sink configures where the generated records are written to.
+type Sink struct {
+	// Type is either "kafka", "file" or "stdout". When writing to files or
+	// stdout, no Kafka cluster is required. Defaults to "kafka".
+	Type   string     `yaml:"type"`
+	File   SinkFile   `yaml:"file"`
+	Stdout SinkStdout `yaml:"stdout"`
+}
+
+// SinkFile configures the file sink, which writes the records of each topic
+// into a separate file.
+type SinkFile struct {
+	// Directory the files are written to. Defaults to "./owlshop-data".
+	Directory string `yaml:"directory"`
+}
+
+// SinkStdout configures the stdout sink (also known as dry-run mode), which
+// prints all records instead of producing them. Topics and schemas are not
+// created in this mode.
+type SinkStdout struct {
+	// Format is either "text" for human-readable output or "json" for one
+	// JSON object per record. Defaults to "text".
+	Format string `yaml:"format"`
+}
+
+// SetDefaults for the sink config.
+func (c *Sink) SetDefaults() {
+	c.Type = SinkTypeKafka
+	c.File.Directory = "./owlshop-data"
+	c.Stdout.Format = SinkStdoutFormatText
+}
+
+// Validate the sink config.
+func (c *Sink) Validate() error {
+	switch c.Type {
+	case SinkTypeKafka:
+	case SinkTypeFile:
+		if c.File.Directory == "" {
+			return fmt.Errorf("file sink requires a directory")
+		}
+	case SinkTypeStdout:
+		if c.Stdout.Format != SinkStdoutFormatText && c.Stdout.Format != SinkStdoutFormatJSON {
+			return fmt.Errorf("unknown stdout format '%v', valid formats are: %v, %v",
+				c.Stdout.Format, SinkStdoutFormatText, SinkStdoutFormatJSON)
+		}
+	default:
+		return fmt.Errorf("unknown sink type '%v', valid types are: %v, %v, %v",
+			c.Type, SinkTypeKafka, SinkTypeFile, SinkTypeStdout)
+	}
+
+	return nil
+}
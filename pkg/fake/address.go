@@ -1,11 +1,9 @@
 package fake
 
 import (
-	"math/rand"
 	"strconv"
 	"time"
 
-	"github.com/brianvoe/gofakeit/v5"
 	"github.com/mroth/weightedrand"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
@@ -19,31 +17,39 @@ const (
 	AddressTypeDelivery AddressType = "DELIVERY"
 )
 
-func NewAddress(customer Customer) Address {
-	address := gofakeit.Address()
+func (g *Generator) NewAddress(customer Customer) Address {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.newAddress(customer)
+}
+
+// newAddress creates a new fake address. The caller must hold the generator's lock.
+func (g *Generator) newAddress(customer Customer) Address {
+	address := g.faker.Address()
 
 	return Address{
 		Version: 0,
-		ID:      gofakeit.UUID(),
+		ID:      g.faker.UUID(),
 		Customer: AddressCustomer{
 			CustomerID:   customer.ID,
 			CustomerType: customer.CustomerType,
 		},
 
 		// Address info
-		Type:                  newAddressType(),
+		Type:                  g.newAddressType(),
 		FirstName:             customer.FirstName,
 		LastName:              customer.LastName,
 		State:                 address.State,
 		Street:                address.Street,
-		HouseNumber:           strconv.Itoa(gofakeit.Number(1, 1000)),
+		HouseNumber:           strconv.Itoa(g.faker.Number(1, 1000)),
 		City:                  address.City,
 		Zip:                   address.Zip,
 		Latitude:              address.Latitude,
 		Longitude:             address.Longitude,
-		Phone:                 gofakeit.PhoneFormatted(),
-		AdditionalAddressInfo: newAdditionalAddressInfo(),
-		CreatedAt:             time.Now(),
+		Phone:                 g.faker.PhoneFormatted(),
+		AdditionalAddressInfo: g.newAdditionalAddressInfo(),
+		CreatedAt:             g.now(),
 		Revision:              0,
 	}
 }
@@ -106,34 +112,24 @@ func (a *AddressCustomer) Protobuf() *shoppb.Address_Customer {
 }
 
 // newAddressType returns an address type based on a weighted random choice
-func newAddressType() AddressType {
-	rand.Seed(time.Now().UTC().UnixNano())
-	c, err := weightedrand.NewChooser(
+func (g *Generator) newAddressType() AddressType {
+	addressType := g.pick(
 		weightedrand.Choice{Item: AddressTypeDelivery, Weight: 20},
 		weightedrand.Choice{Item: AddressTypeInvoice, Weight: 80},
-	)
-	if err != nil {
-		panic(err)
-	}
-	addressType := c.Pick().(AddressType)
+	).(AddressType)
 	return addressType
 }
 
-func newAdditionalAddressInfo() string {
-	rand.Seed(time.Now().UTC().UnixNano())
-	c, err := weightedrand.NewChooser(
+func (g *Generator) newAdditionalAddressInfo() string {
+	addressInfo := g.pick(
 		weightedrand.Choice{Item: "", Weight: 200},
 
 		// 100 Sum
-		weightedrand.Choice{Item: gofakeit.RandomString([]string{"a", "b", "c"}), Weight: 60},
-		weightedrand.Choice{Item: gofakeit.HipsterWord(), Weight: 15},
-		weightedrand.Choice{Item: gofakeit.HipsterSentence(4), Weight: 10},
-		weightedrand.Choice{Item: gofakeit.Noun(), Weight: 14},
-		weightedrand.Choice{Item: gofakeit.Emoji(), Weight: 1},
-	)
-	if err != nil {
-		panic(err)
-	}
-	addressInfo := c.Pick().(string)
+		weightedrand.Choice{Item: g.faker.RandomString([]string{"a", "b", "c"}), Weight: 60},
+		weightedrand.Choice{Item: g.faker.HipsterWord(), Weight: 15},
+		weightedrand.Choice{Item: g.faker.HipsterSentence(4), Weight: 10},
+		weightedrand.Choice{Item: g.faker.Noun(), Weight: 14},
+		weightedrand.Choice{Item: g.faker.Emoji(), Weight: 1},
+	).(string)
 	return addressInfo
 }
@@ -1,7 +1,6 @@
 package fake
 
 import (
-	"github.com/brianvoe/gofakeit/v5"
 	"github.com/mroth/weightedrand"
 
 	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
@@ -52,37 +51,48 @@ func (c *Customer) Protobuf() *shoppb.Customer {
 	}
 }
 
-func NewCustomer() Customer {
-	person := gofakeit.Person()
+func (g *Generator) NewCustomer() Customer {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	person := g.faker.Person()
 
 	var companyName *string
-	customerType := newCustomerType()
+	customerType := g.newCustomerType()
 	if customerType == CustomerTypeBusiness {
-		company := gofakeit.Company()
+		company := g.faker.Company()
 		companyName = &company
 	}
 
 	return Customer{
 		Version:      0,
-		ID:           gofakeit.UUID(),
+		ID:           g.faker.UUID(),
 		FirstName:    person.FirstName,
 		LastName:     person.LastName,
 		Gender:       person.Gender,
 		CompanyName:  companyName,
-		Email:        gofakeit.Email(),
+		Email:        g.faker.Email(),
 		CustomerType: customerType,
 	}
 }
 
+// ModifyCustomer returns a copy of the given customer with a new last name
+// and an incremented revision.
+func (g *Generator) ModifyCustomer(customer Customer) Customer {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	customer.LastName = g.faker.LastName()
+	customer.Revision++
+
+	return customer
+}
+
 // newCustomerType returns a customer type based on a weighted random choice
-func newCustomerType() CustomerType {
-	c, err := weightedrand.NewChooser(
+func (g *Generator) newCustomerType() CustomerType {
+	customerType := g.pick(
 		weightedrand.Choice{Item: CustomerTypePersonal, Weight: 99},
 		weightedrand.Choice{Item: CustomerTypeBusiness, Weight: 1},
-	)
-	if err != nil {
-		panic(err)
-	}
-	customerType := c.Pick().(CustomerType)
+	).(CustomerType)
 	return customerType
 }
@@ -1,9 +1,11 @@
 package fake
 
 import (
-	"github.com/brianvoe/gofakeit/v5"
-	"github.com/mroth/weightedrand"
 	"net/http"
+
+	"github.com/mroth/weightedrand"
+
+	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
 )
 
 type FrontendEvent struct {
@@ -19,51 +21,66 @@ type FrontendEvent struct {
 	Headers         map[string]string     `json:"headers"`
 }
 
+func (e *FrontendEvent) Protobuf() *shoppb.FrontendEvent {
+	return &shoppb.FrontendEvent{
+		Version:         int32(e.Version),
+		RequestedUrl:    e.RequestedURL,
+		Method:          e.Method,
+		CorrelationId:   e.CorrelationID,
+		IpAddress:       e.IPAddress,
+		RequestDuration: int32(e.RequestDuration),
+		Response: &shoppb.FrontendEvent_Response{
+			Size:       int32(e.Response.Size),
+			StatusCode: int32(e.Response.StatusCode),
+		},
+		Headers: e.Headers,
+	}
+}
+
 type FrontendEventResponse struct {
 	Size       int `json:"size"`
 	StatusCode int `json:"statusCode"`
 }
 
-func NewFrontendEvent() FrontendEvent {
+func (g *Generator) NewFrontendEvent() FrontendEvent {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
 	return FrontendEvent{
 		Version:         0,
-		RequestedURL:    gofakeit.URL(),
-		Method:          gofakeit.HTTPMethod(),
-		CorrelationID:   gofakeit.UUID(),
-		IPAddress:       gofakeit.IPv4Address(),
-		RequestDuration: gofakeit.Number(1, 1500),
+		RequestedURL:    g.faker.URL(),
+		Method:          g.faker.HTTPMethod(),
+		CorrelationID:   g.faker.UUID(),
+		IPAddress:       g.faker.IPv4Address(),
+		RequestDuration: g.faker.Number(1, 1500),
 		Response: FrontendEventResponse{
-			Size:       gofakeit.Number(40, 2500),
-			StatusCode: newStatusCode(),
+			Size:       g.faker.Number(40, 2500),
+			StatusCode: g.newStatusCode(),
 		},
-		Headers: newHTTPHeaders(),
+		Headers: g.newHTTPHeaders(),
 	}
 }
 
-func newHTTPHeaders() map[string]string {
+func (g *Generator) newHTTPHeaders() map[string]string {
 	return map[string]string{
-		"user-agent":      gofakeit.UserAgent(),
+		"user-agent":      g.faker.UserAgent(),
 		"accept":          "*/*",
 		"accept-encoding": "gzip",
 		"cache-control":   "max-age=0",
-		"origin":          gofakeit.URL(),
-		"referrer":        gofakeit.URL(),
+		"origin":          g.faker.URL(),
+		"referrer":        g.faker.URL(),
 	}
 }
 
 // newStatusCode returns a weighted status code
-func newStatusCode() int {
-	c, err := weightedrand.NewChooser(
+func (g *Generator) newStatusCode() int {
+	statusCode := g.pick(
 		weightedrand.Choice{Item: http.StatusOK, Weight: 940},
 		weightedrand.Choice{Item: http.StatusMovedPermanently, Weight: 10},
 		weightedrand.Choice{Item: http.StatusInternalServerError, Weight: 5},
 		weightedrand.Choice{Item: http.StatusServiceUnavailable, Weight: 5},
 		weightedrand.Choice{Item: http.StatusNotFound, Weight: 40},
 		weightedrand.Choice{Item: http.StatusBadRequest, Weight: 2},
-	)
-	if err != nil {
-		panic(err)
-	}
-	statusCode := c.Pick().(int)
+	).(int)
 	return statusCode
 }
@@ -0,0 +1,81 @@
+package fake
+
+import (
+	"sync"
+	"time"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/mroth/weightedrand"
+)
+
+// simulatedEpoch is the first timestamp returned by the simulated clock of
+// seeded generators.
+var simulatedEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Generator generates fake shop entities. All random data is derived from a
+// single random source, so that two generators with the same seed generate the
+// same sequence of entities. Generator is safe for concurrent use, but the
+// sequence is only reproducible if the calls are made in the same order.
+type Generator struct {
+	mu    sync.Mutex
+	faker *gofakeit.Faker
+	now   func() time.Time
+}
+
+// NewGenerator creates a new Generator. If the seed is 0, a random seed and the
+// system clock are used. Otherwise, the generator uses the given seed and a
+// simulated clock, which starts at a fixed date and advances by one second on
+// every use, so that the generated timestamps are reproducible as well.
+func NewGenerator(seed int64) *Generator {
+	if seed == 0 {
+		return &Generator{
+			faker: gofakeit.NewUnlocked(0),
+			now:   time.Now,
+		}
+	}
+
+	return &Generator{
+		faker: gofakeit.NewUnlocked(seed),
+		now:   newSimulatedClock(simulatedEpoch, time.Second),
+	}
+}
+
+// Now returns the current time of the generator's clock. All timestamps of the
+// generated entities and their records must be taken from this clock, so that
+// they are reproducible for seeded generators.
+func (g *Generator) Now() time.Time {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.now()
+}
+
+// Pick returns a weighted random item from the given chooser using the
+// generator's random source.
+func (g *Generator) Pick(chooser *weightedrand.Chooser) interface{} {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return chooser.PickSource(g.faker.Rand)
+}
+
+// pick creates a chooser from the given choices and returns a weighted random
+// item. The caller must hold the generator's lock.
+func (g *Generator) pick(choices ...weightedrand.Choice) interface{} {
+	c, err := weightedrand.NewChooser(choices...)
+	if err != nil {
+		panic(err)
+	}
+
+	return c.PickSource(g.faker.Rand)
+}
+
+// newSimulatedClock returns a clock that starts at the given time and advances by
+// the given step every time it is called. It is not safe for concurrent use.
+func newSimulatedClock(start time.Time, step time.Duration) func() time.Time {
+	now := start.Add(-step)
+	return func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+}
@@ -3,30 +3,107 @@ package fake
 import (
 	"time"
 
-	"github.com/brianvoe/gofakeit/v5"
+	"github.com/mroth/weightedrand"
 	"google.golang.org/protobuf/types/known/timestamppb"
 
+	"github.com/cloudhut/owl-shop/pkg/config"
 	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
 )
 
-func NewOrder(customer Customer) Order {
+type OrderStatus string
+
+const (
+	OrderStatusCreated   OrderStatus = "CREATED"
+	OrderStatusPaid      OrderStatus = "PAID"
+	OrderStatusShipped   OrderStatus = "SHIPPED"
+	OrderStatusDelivered OrderStatus = "DELIVERED"
+	OrderStatusCompleted OrderStatus = "COMPLETED"
+	OrderStatusCancelled OrderStatus = "CANCELLED"
+	OrderStatusReturned  OrderStatus = "RETURNED"
+)
+
+// orderTransitions are the weighted next states of the order statuses that are
+// advanced by the shop. Shipped orders are advanced once they have been
+// delivered, see DeliverOrder.
+var orderTransitions = map[OrderStatus][]weightedrand.Choice{
+	OrderStatusCreated: {
+		{Item: OrderStatusPaid, Weight: 90},
+		{Item: OrderStatusCancelled, Weight: 10},
+	},
+	OrderStatusPaid: {
+		{Item: OrderStatusShipped, Weight: 95},
+		{Item: OrderStatusCancelled, Weight: 5},
+	},
+	OrderStatusDelivered: {
+		{Item: OrderStatusCompleted, Weight: 92},
+		{Item: OrderStatusReturned, Weight: 8},
+	},
+}
+
+func (g *Generator) NewOrder(customer Customer) Order {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
 	return Order{
 		Version:       0,
-		ID:            gofakeit.UUID(),
-		CreatedAt:     time.Now(),
-		LastUpdatedAt: time.Now(),
+		ID:            g.faker.UUID(),
+		CreatedAt:     now,
+		LastUpdatedAt: now,
 		DeliveredAt:   nil,
 		CompletedAt:   nil,
 		Customer:      customer,
-		OrderValue:    gofakeit.Number(5000, 250000),
-		LineItems:     newOrderLineItems(),
+		OrderValue:    g.faker.Number(5000, 250000),
+		LineItems:     g.newOrderLineItems(),
 		Payment: OrderPayment{
-			PaymentID: gofakeit.UUID(),
-			Method:    gofakeit.RandomString([]string{"CASH", "DEBIT", "CREDIT_CARD", "PAYPAL"}),
+			PaymentID: g.faker.UUID(),
+			Method:    g.faker.RandomString(config.PaymentMethods()),
 		},
-		DeliveryAddress: NewAddress(customer),
+		DeliveryAddress: g.newAddress(customer),
 		Revision:        0,
+		Status:          OrderStatusCreated,
+	}
+}
+
+// AdvanceOrder returns a copy of the given order that has progressed to a
+// randomly picked next status, with an incremented revision and updated
+// timestamps. Shipped and closed orders are returned unchanged.
+func (g *Generator) AdvanceOrder(order Order) Order {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	transitions, exists := orderTransitions[order.Status]
+	if !exists {
+		return order
+	}
+
+	now := g.now()
+	order.Status = g.pick(transitions...).(OrderStatus)
+	order.LastUpdatedAt = now
+	order.Revision++
+	if order.Status == OrderStatusCompleted {
+		order.CompletedAt = &now
+	}
+
+	return order
+}
+
+// DeliverOrder returns a copy of the given shipped order that has been delivered
+// at the given time. Orders that haven't been shipped are returned unchanged.
+func (g *Generator) DeliverOrder(order Order, deliveredAt time.Time) Order {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if order.Status != OrderStatusShipped {
+		return order
 	}
+
+	order.Status = OrderStatusDelivered
+	order.DeliveredAt = &deliveredAt
+	order.LastUpdatedAt = g.now()
+	order.Revision++
+
+	return order
 }
 
 type Order struct {
@@ -44,7 +121,17 @@ type Order struct {
 	LineItems       []OrderLineItem `json:"lineItems"`
 	Payment         OrderPayment    `json:"payment"`
 	DeliveryAddress Address         `json:"deliveryAddress"`
-	Revision        int             `json:"revision"`
+	Revision        int             `json:"revision"` // Each status change of the order increments the revision
+	Status          OrderStatus     `json:"status"`
+}
+
+// IsOpen returns true if the order's status can still change.
+func (o *Order) IsOpen() bool {
+	switch o.Status {
+	case OrderStatusCompleted, OrderStatusCancelled, OrderStatusReturned:
+		return false
+	}
+	return true
 }
 
 func (o *Order) Protobuf() *shoppb.Order {
@@ -66,29 +153,51 @@ func (o *Order) Protobuf() *shoppb.Order {
 		Payment:         o.Payment.Protobuf(),
 		DeliveryAddress: o.DeliveryAddress.Protobuf(),
 		Revision:        int32(o.Revision),
+		Status:          o.Status.Protobuf(),
 	}
 
 	return &order
 }
 
-func newOrderLineItems() []OrderLineItem {
-	itemCount := gofakeit.Number(8, 45)
+func (s OrderStatus) Protobuf() shoppb.Order_Status {
+	switch s {
+	case OrderStatusCreated:
+		return shoppb.Order_STATUS_CREATED
+	case OrderStatusPaid:
+		return shoppb.Order_STATUS_PAID
+	case OrderStatusShipped:
+		return shoppb.Order_STATUS_SHIPPED
+	case OrderStatusDelivered:
+		return shoppb.Order_STATUS_DELIVERED
+	case OrderStatusCompleted:
+		return shoppb.Order_STATUS_COMPLETED
+	case OrderStatusCancelled:
+		return shoppb.Order_STATUS_CANCELLED
+	case OrderStatusReturned:
+		return shoppb.Order_STATUS_RETURNED
+	}
+
+	return shoppb.Order_STATUS_UNSPECIFIED
+}
+
+func (g *Generator) newOrderLineItems() []OrderLineItem {
+	itemCount := g.faker.Number(8, 45)
 	items := make([]OrderLineItem, itemCount)
 	for i := 0; i < itemCount; i++ {
-		items[i] = newOrderLineItem()
+		items[i] = g.newOrderLineItem()
 	}
 
 	return items
 }
 
-func newOrderLineItem() OrderLineItem {
-	quantity := gofakeit.Number(1, 500)
-	unitPrice := gofakeit.Number(1, 1000)
+func (g *Generator) newOrderLineItem() OrderLineItem {
+	quantity := g.faker.Number(1, 500)
+	unitPrice := g.faker.Number(1, 1000)
 	return OrderLineItem{
-		ArticleID:    gofakeit.UUID(),
-		Name:         gofakeit.Vegetable(),
+		ArticleID:    g.faker.UUID(),
+		Name:         g.faker.Vegetable(),
 		Quantity:     quantity,
-		QuantityUnit: gofakeit.RandomString([]string{"pieces", "gram"}),
+		QuantityUnit: g.faker.RandomString([]string{"pieces", "gram"}),
 		UnitPrice:    unitPrice,
 		TotalPrice:   quantity * unitPrice,
 	}
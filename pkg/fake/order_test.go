@@ -0,0 +1,82 @@
+package fake
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAdvanceOrder(t *testing.T) {
+	tests := []struct {
+		status       OrderStatus
+		wantStatuses []OrderStatus
+	}{
+		{status: OrderStatusCreated, wantStatuses: []OrderStatus{OrderStatusPaid, OrderStatusCancelled}},
+		{status: OrderStatusPaid, wantStatuses: []OrderStatus{OrderStatusShipped, OrderStatusCancelled}},
+		{status: OrderStatusDelivered, wantStatuses: []OrderStatus{OrderStatusCompleted, OrderStatusReturned}},
+		// Shipped orders wait for their delivery and closed orders never change
+		{status: OrderStatusShipped, wantStatuses: []OrderStatus{OrderStatusShipped}},
+		{status: OrderStatusCompleted, wantStatuses: []OrderStatus{OrderStatusCompleted}},
+		{status: OrderStatusCancelled, wantStatuses: []OrderStatus{OrderStatusCancelled}},
+		{status: OrderStatusReturned, wantStatuses: []OrderStatus{OrderStatusReturned}},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			generator := NewGenerator(1)
+			order := generator.NewOrder(generator.NewCustomer())
+			order.Status = tt.status
+
+			seen := make(map[OrderStatus]bool)
+			for i := 0; i < 200; i++ {
+				advanced := generator.AdvanceOrder(order)
+				seen[advanced.Status] = true
+
+				if advanced.Status == order.Status {
+					if advanced.Revision != order.Revision {
+						t.Fatalf("unchanged order has revision %v, want %v", advanced.Revision, order.Revision)
+					}
+					continue
+				}
+				if advanced.Revision != order.Revision+1 {
+					t.Errorf("advanced order has revision %v, want %v", advanced.Revision, order.Revision+1)
+				}
+				if !advanced.LastUpdatedAt.After(order.LastUpdatedAt) {
+					t.Errorf("last updated at has not been updated")
+				}
+				if (advanced.Status == OrderStatusCompleted) != (advanced.CompletedAt != nil) {
+					t.Errorf("order with status %v has completed at %v", advanced.Status, advanced.CompletedAt)
+				}
+			}
+
+			if len(seen) != len(tt.wantStatuses) {
+				t.Errorf("got statuses %v, want %v", seen, tt.wantStatuses)
+			}
+			for _, status := range tt.wantStatuses {
+				if !seen[status] {
+					t.Errorf("status %v has never been reached", status)
+				}
+			}
+		})
+	}
+}
+
+func TestDeliverOrder(t *testing.T) {
+	generator := NewGenerator(1)
+	order := generator.NewOrder(generator.NewCustomer())
+	deliveredAt := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	if unchanged := generator.DeliverOrder(order, deliveredAt); unchanged.Status != OrderStatusCreated {
+		t.Errorf("order that hasn't been shipped has status %v, want %v", unchanged.Status, OrderStatusCreated)
+	}
+
+	order.Status = OrderStatusShipped
+	delivered := generator.DeliverOrder(order, deliveredAt)
+	if delivered.Status != OrderStatusDelivered {
+		t.Errorf("status = %v, want %v", delivered.Status, OrderStatusDelivered)
+	}
+	if delivered.DeliveredAt == nil || !delivered.DeliveredAt.Equal(deliveredAt) {
+		t.Errorf("delivered at = %v, want %v", delivered.DeliveredAt, deliveredAt)
+	}
+	if delivered.Revision != order.Revision+1 {
+		t.Errorf("revision = %v, want %v", delivered.Revision, order.Revision+1)
+	}
+}
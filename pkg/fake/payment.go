@@ -0,0 +1,118 @@
+package fake
+
+import (
+	"time"
+
+	"github.com/mroth/weightedrand"
+)
+
+type PaymentStatus string
+
+const (
+	PaymentStatusAuthorized PaymentStatus = "AUTHORIZED"
+	PaymentStatusDeclined   PaymentStatus = "DECLINED"
+	PaymentStatusCaptured   PaymentStatus = "CAPTURED"
+	PaymentStatusRefunded   PaymentStatus = "REFUNDED"
+)
+
+// declineReasons are the weighted reason codes of declined authorizations.
+var declineReasons = []weightedrand.Choice{
+	{Item: "INSUFFICIENT_FUNDS", Weight: 40},
+	{Item: "DO_NOT_HONOR", Weight: 20},
+	{Item: "EXPIRED_CARD", Weight: 15},
+	{Item: "SUSPECTED_FRAUD", Weight: 10},
+	{Item: "LIMIT_EXCEEDED", Weight: 10},
+	{Item: "INVALID_ACCOUNT", Weight: 5},
+}
+
+// Payment is the payment of an order. Each record of a payment is an event of
+// its lifecycle: AUTHORIZED or DECLINED, followed by CAPTURED and possibly
+// REFUNDED.
+type Payment struct {
+	// VersionedStruct
+	Version int `json:"version"`
+
+	ID         string        `json:"id"` // Matches the payment id of the order
+	OrderID    string        `json:"orderId"`
+	CustomerID string        `json:"customerId"`
+	Method     string        `json:"method"`
+	Amount     int           `json:"amount"`
+	Status     PaymentStatus `json:"status"`
+	// ReasonCode is set if the authorization has been declined.
+	ReasonCode  *string   `json:"reasonCode"`
+	Attempt     int       `json:"attempt"` // Each authorization attempt increments the attempt
+	ProcessedAt time.Time `json:"processedAt"`
+	Revision    int       `json:"revision"` // Each event of the payment increments the revision
+}
+
+// AuthorizePayment creates the payment of the given order, whose authorization
+// is declined with the given probability.
+func (g *Generator) AuthorizePayment(order Order, failureRate float64) Payment {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment := Payment{
+		Version:     0,
+		ID:          order.Payment.PaymentID,
+		OrderID:     order.ID,
+		CustomerID:  order.Customer.ID,
+		Method:      order.Payment.Method,
+		Amount:      order.OrderValue,
+		Status:      PaymentStatusAuthorized,
+		ReasonCode:  nil,
+		Attempt:     1,
+		ProcessedAt: g.now(),
+		Revision:    0,
+	}
+	if g.faker.Float64Range(0, 1) < failureRate {
+		reason := g.pick(declineReasons...).(string)
+		payment.Status = PaymentStatusDeclined
+		payment.ReasonCode = &reason
+	}
+
+	return payment
+}
+
+// ReauthorizePayment returns a copy of the given declined payment that has been
+// authorized by another attempt. Other payments are returned unchanged.
+func (g *Generator) ReauthorizePayment(payment Payment) Payment {
+	if payment.Status != PaymentStatusDeclined {
+		return payment
+	}
+	payment = g.advancePayment(payment, PaymentStatusAuthorized)
+	payment.Attempt++
+
+	return payment
+}
+
+// CapturePayment returns a copy of the given authorized payment that has been
+// captured. Other payments are returned unchanged.
+func (g *Generator) CapturePayment(payment Payment) Payment {
+	if payment.Status != PaymentStatusAuthorized {
+		return payment
+	}
+
+	return g.advancePayment(payment, PaymentStatusCaptured)
+}
+
+// RefundPayment returns a copy of the given captured payment that has been
+// refunded. Other payments are returned unchanged.
+func (g *Generator) RefundPayment(payment Payment) Payment {
+	if payment.Status != PaymentStatusCaptured {
+		return payment
+	}
+
+	return g.advancePayment(payment, PaymentStatusRefunded)
+}
+
+func (g *Generator) advancePayment(payment Payment, status PaymentStatus) Payment {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	payment.Status = status
+	payment.ReasonCode = nil
+	payment.ProcessedAt = g.now()
+	payment.Revision++
+
+	return payment
+}
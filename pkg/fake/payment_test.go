@@ -0,0 +1,71 @@
+package fake
+
+import "testing"
+
+func TestAuthorizePayment(t *testing.T) {
+	tests := []struct {
+		name        string
+		failureRate float64
+		wantStatus  PaymentStatus
+	}{
+		{name: "never declined", failureRate: 0, wantStatus: PaymentStatusAuthorized},
+		{name: "always declined", failureRate: 1, wantStatus: PaymentStatusDeclined},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			generator := NewGenerator(1)
+			order := generator.NewOrder(generator.NewCustomer())
+
+			payment := generator.AuthorizePayment(order, tt.failureRate)
+			if payment.Status != tt.wantStatus {
+				t.Errorf("status = %v, want %v", payment.Status, tt.wantStatus)
+			}
+			if (payment.Status == PaymentStatusDeclined) != (payment.ReasonCode != nil) {
+				t.Errorf("payment with status %v has reason code %v", payment.Status, payment.ReasonCode)
+			}
+			if payment.ID != order.Payment.PaymentID || payment.OrderID != order.ID || payment.Amount != order.OrderValue {
+				t.Errorf("payment %+v does not belong to order %v", payment, order.ID)
+			}
+		})
+	}
+}
+
+func TestPaymentLifecycle(t *testing.T) {
+	generator := NewGenerator(1)
+	declined := generator.AuthorizePayment(generator.NewOrder(generator.NewCustomer()), 1)
+
+	tests := []struct {
+		name        string
+		transition  func(Payment) Payment
+		wantStatus  PaymentStatus
+		wantAttempt int
+	}{
+		{name: "capture declined", transition: generator.CapturePayment, wantStatus: PaymentStatusDeclined, wantAttempt: 1},
+		{name: "refund declined", transition: generator.RefundPayment, wantStatus: PaymentStatusDeclined, wantAttempt: 1},
+		{name: "reauthorize declined", transition: generator.ReauthorizePayment, wantStatus: PaymentStatusAuthorized, wantAttempt: 2},
+		{name: "reauthorize authorized", transition: generator.ReauthorizePayment, wantStatus: PaymentStatusAuthorized, wantAttempt: 2},
+		{name: "refund authorized", transition: generator.RefundPayment, wantStatus: PaymentStatusAuthorized, wantAttempt: 2},
+		{name: "capture authorized", transition: generator.CapturePayment, wantStatus: PaymentStatusCaptured, wantAttempt: 2},
+		{name: "capture captured", transition: generator.CapturePayment, wantStatus: PaymentStatusCaptured, wantAttempt: 2},
+		{name: "refund captured", transition: generator.RefundPayment, wantStatus: PaymentStatusRefunded, wantAttempt: 2},
+		{name: "refund refunded", transition: generator.RefundPayment, wantStatus: PaymentStatusRefunded, wantAttempt: 2},
+	}
+	payment := declined
+	for _, tt := range tests {
+		next := tt.transition(payment)
+		if next.Status != tt.wantStatus || next.Attempt != tt.wantAttempt {
+			t.Fatalf("%v: status = %v, attempt = %v, want %v, %v", tt.name, next.Status, next.Attempt, tt.wantStatus, tt.wantAttempt)
+		}
+		wantRevision := payment.Revision
+		if next.Status != payment.Status {
+			wantRevision++
+			if next.ReasonCode != nil {
+				t.Errorf("%v: reason code %v has not been cleared", tt.name, *next.ReasonCode)
+			}
+		}
+		if next.Revision != wantRevision {
+			t.Errorf("%v: revision = %v, want %v", tt.name, next.Revision, wantRevision)
+		}
+		payment = next
+	}
+}
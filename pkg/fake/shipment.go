@@ -0,0 +1,91 @@
+package fake
+
+import (
+	"strings"
+	"time"
+)
+
+type ShipmentStatus string
+
+const (
+	ShipmentStatusLabelCreated   ShipmentStatus = "LABEL_CREATED"
+	ShipmentStatusPickedUp       ShipmentStatus = "PICKED_UP"
+	ShipmentStatusInTransit      ShipmentStatus = "IN_TRANSIT"
+	ShipmentStatusOutForDelivery ShipmentStatus = "OUT_FOR_DELIVERY"
+	ShipmentStatusDelivered      ShipmentStatus = "DELIVERED"
+)
+
+// nextShipmentStatus maps each status to the status of the next scan.
+var nextShipmentStatus = map[ShipmentStatus]ShipmentStatus{
+	ShipmentStatusLabelCreated:   ShipmentStatusPickedUp,
+	ShipmentStatusPickedUp:       ShipmentStatusInTransit,
+	ShipmentStatusInTransit:      ShipmentStatusOutForDelivery,
+	ShipmentStatusOutForDelivery: ShipmentStatusDelivered,
+}
+
+// Shipment is the delivery of an order. Each record of a shipment is a status
+// scan of the carrier.
+type Shipment struct {
+	// VersionedStruct
+	Version int `json:"version"`
+
+	ID             string         `json:"id"`
+	OrderID        string         `json:"orderId"`
+	Carrier        string         `json:"carrier"`
+	TrackingNumber string         `json:"trackingNumber"`
+	Status         ShipmentStatus `json:"status"`
+	ScannedAt      time.Time      `json:"scannedAt"`
+	// Location is the city where the last scan took place.
+	Location    string `json:"location"`
+	Destination string `json:"destination"`
+	Revision    int    `json:"revision"` // Each scan of the shipment increments the revision
+}
+
+// NewShipment creates the shipment of the given order, whose label has just been created.
+func (g *Generator) NewShipment(order Order) Shipment {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	carrier := g.faker.RandomString([]string{"DHL", "UPS", "FEDEX", "DPD", "USPS"})
+	return Shipment{
+		Version:        0,
+		ID:             g.faker.UUID(),
+		OrderID:        order.ID,
+		Carrier:        carrier,
+		TrackingNumber: carrier[:2] + strings.ToUpper(g.faker.LetterN(2)) + g.faker.DigitN(10),
+		Status:         ShipmentStatusLabelCreated,
+		ScannedAt:      g.now(),
+		Location:       g.faker.City(),
+		Destination:    order.DeliveryAddress.City,
+		Revision:       0,
+	}
+}
+
+// ScanShipment returns a copy of the given shipment that has been scanned with
+// the next status. Delivered shipments are returned unchanged.
+func (g *Generator) ScanShipment(shipment Shipment) Shipment {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	status, exists := nextShipmentStatus[shipment.Status]
+	if !exists {
+		return shipment
+	}
+
+	shipment.Status = status
+	shipment.ScannedAt = g.now()
+	shipment.Revision++
+	switch status {
+	case ShipmentStatusOutForDelivery, ShipmentStatusDelivered:
+		shipment.Location = shipment.Destination
+	default:
+		shipment.Location = g.faker.City()
+	}
+
+	return shipment
+}
+
+// IsDelivered returns true if the shipment has reached its destination.
+func (s *Shipment) IsDelivered() bool {
+	return s.Status == ShipmentStatusDelivered
+}
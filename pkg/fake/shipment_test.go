@@ -0,0 +1,45 @@
+package fake
+
+import "testing"
+
+func TestScanShipment(t *testing.T) {
+	generator := NewGenerator(1)
+	order := generator.NewOrder(generator.NewCustomer())
+	shipment := generator.NewShipment(order)
+	if shipment.OrderID != order.ID || shipment.Status != ShipmentStatusLabelCreated {
+		t.Fatalf("new shipment = %+v, want label created for order %v", shipment, order.ID)
+	}
+
+	wantStatuses := []ShipmentStatus{
+		ShipmentStatusPickedUp,
+		ShipmentStatusInTransit,
+		ShipmentStatusOutForDelivery,
+		ShipmentStatusDelivered,
+	}
+	for i, wantStatus := range wantStatuses {
+		if shipment.IsDelivered() {
+			t.Fatalf("shipment with status %v is delivered", shipment.Status)
+		}
+		scanned := generator.ScanShipment(shipment)
+		if scanned.Status != wantStatus {
+			t.Fatalf("scan %d: status = %v, want %v", i+1, scanned.Status, wantStatus)
+		}
+		if scanned.Revision != shipment.Revision+1 {
+			t.Errorf("scan %d: revision = %v, want %v", i+1, scanned.Revision, shipment.Revision+1)
+		}
+		if !scanned.ScannedAt.After(shipment.ScannedAt) {
+			t.Errorf("scan %d: scanned at has not been updated", i+1)
+		}
+		shipment = scanned
+	}
+
+	if !shipment.IsDelivered() {
+		t.Errorf("shipment with status %v is not delivered", shipment.Status)
+	}
+	if shipment.Location != shipment.Destination {
+		t.Errorf("delivered shipment is located in %v, want %v", shipment.Location, shipment.Destination)
+	}
+	if unchanged := generator.ScanShipment(shipment); unchanged != shipment {
+		t.Errorf("delivered shipment has been scanned again")
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/pkg/sasl"
 	"github.com/twmb/franz-go/pkg/sasl/kerberos"
+	"github.com/twmb/franz-go/pkg/sasl/oauth"
 	"github.com/twmb/franz-go/pkg/sasl/plain"
 	"github.com/twmb/franz-go/pkg/sasl/scram"
 	"github.com/twmb/franz-go/plugin/kzap"
@@ -26,6 +27,7 @@ func NewKgoConfig(cfg *config.Kafka, logger *zap.Logger) ([]kgo.Opt, error) {
 		kgo.RecordDeliveryTimeout(10 * time.Second),
 		kgo.WithLogger(kzap.New(logger)),
 	}
+	opts = append(opts, producerOpts(cfg.Producer)...)
 
 	// Configure SASL
 	if cfg.SASL.Enabled {
@@ -86,6 +88,12 @@ func NewKgoConfig(cfg *config.Kafka, logger *zap.Logger) ([]kgo.Opt, error) {
 			}.AsMechanism()
 			opts = append(opts, kgo.SASL(kerberosMechanism))
 		}
+
+		// OAuth
+		if cfg.SASL.Mechanism == config.SASLMechanismOAuthBearer {
+			mechanism := oauth.Oauth(newOAuthFn(cfg.SASL.OAuth))
+			opts = append(opts, kgo.SASL(mechanism))
+		}
 	}
 
 	// Configure TLS
@@ -100,3 +108,50 @@ func NewKgoConfig(cfg *config.Kafka, logger *zap.Logger) ([]kgo.Opt, error) {
 
 	return opts, nil
 }
+
+// producerOpts returns the kgo options for the given producer config.
+func producerOpts(cfg config.KafkaProducer) []kgo.Opt {
+	opts := []kgo.Opt{
+		kgo.ProducerLinger(cfg.Linger),
+		kgo.ProducerBatchMaxBytes(cfg.MaxBatchBytes),
+		kgo.MaxBufferedRecords(cfg.MaxBufferedRecords),
+	}
+
+	switch cfg.Compression {
+	case config.ProducerCompressionNone:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.NoCompression()))
+	case config.ProducerCompressionGzip:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.GzipCompression()))
+	case config.ProducerCompressionSnappy:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.SnappyCompression()))
+	case config.ProducerCompressionLz4:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.Lz4Compression()))
+	case config.ProducerCompressionZstd:
+		opts = append(opts, kgo.ProducerBatchCompression(kgo.ZstdCompression()))
+	}
+
+	switch cfg.RequiredAcks {
+	case config.ProducerAcksNone:
+		opts = append(opts, kgo.RequiredAcks(kgo.NoAck()))
+	case config.ProducerAcksLeader:
+		opts = append(opts, kgo.RequiredAcks(kgo.LeaderAck()))
+	case config.ProducerAcksAll:
+		opts = append(opts, kgo.RequiredAcks(kgo.AllISRAcks()))
+	}
+	if !cfg.Idempotence {
+		opts = append(opts, kgo.DisableIdempotentWrite())
+	}
+
+	switch cfg.Partitioner {
+	case config.ProducerPartitionerUniformBytes:
+		opts = append(opts, kgo.RecordPartitioner(kgo.UniformBytesPartitioner(64<<10, true, true, nil)))
+	case config.ProducerPartitionerSticky:
+		opts = append(opts, kgo.RecordPartitioner(kgo.StickyPartitioner()))
+	case config.ProducerPartitionerRoundRobin:
+		opts = append(opts, kgo.RecordPartitioner(kgo.RoundRobinPartitioner()))
+	case config.ProducerPartitionerMurmur2:
+		opts = append(opts, kgo.RecordPartitioner(kgo.StickyKeyPartitioner(nil)))
+	}
+
+	return opts
+}
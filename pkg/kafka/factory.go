@@ -44,3 +44,24 @@ func (s *Factory) NewKafkaClient(
 
 	return kafkaClient, nil
 }
+
+// NewGroupTransactSession creates a new transactional Kafka client that consumes
+// in a consumer group. The client id is used as transactional id as well.
+func (s *Factory) NewGroupTransactSession(
+	clientID string,
+	additionalOpts ...kgo.Opt,
+) (*kgo.GroupTransactSession, error) {
+	kgoOpts, err := NewKgoConfig(&s.Config, s.Logger.Named(clientID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a valid kafka client config: %w", err)
+	}
+	kgoOpts = append(kgoOpts, kgo.ClientID(clientID), kgo.TransactionalID(clientID))
+	kgoOpts = append(kgoOpts, additionalOpts...)
+
+	session, err := kgo.NewGroupTransactSession(kgoOpts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka transact session: %w", err)
+	}
+
+	return session, nil
+}
@@ -3,15 +3,25 @@ package kafka
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/twmb/franz-go/pkg/kadm"
 	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
 )
 
 // ReconcileTopic ensures a topic with the given parameters exist in the target cluster.
+// If the topic already exists, the reconcile mode determines how differences between
+// the existing and the given partition count and topic configs are handled: they are
+// either ignored (createOnly), logged (warn) or applied (enforce). The partition count
+// can only be increased and the replication factor is never changed.
 func ReconcileTopic(
 	ctx context.Context,
 	kafkaClient *kgo.Client,
+	logger *zap.Logger,
+	mode string,
 	topicName string,
 	partitions int32,
 	replicationFactor int16,
@@ -41,8 +51,182 @@ func ReconcileTopic(
 		if topicRes.Err != nil {
 			return fmt.Errorf("failed to create topic: %w", topicRes.Err)
 		}
+		return nil
+	}
+
+	if mode == config.ReconcileModeCreateOnly {
+		return nil
+	}
+	logger = logger.With(zap.String("topic_name", topicName), zap.String("reconcile_mode", mode))
+
+	topic := topicDetails[topicName]
+	if topic.Err != nil {
+		return fmt.Errorf("failed to describe topic: %w", topic.Err)
+	}
+	if err := reconcilePartitions(ctx, adminClient, logger, mode, topic, partitions, replicationFactor); err != nil {
+		return err
+	}
+
+	return reconcileConfigs(ctx, adminClient, logger, mode, topicName, configs)
+}
+
+// reconcilePartitions compares the partition count and replication factor of an existing
+// topic. A value of -1 refers to the broker's default and is therefore not compared.
+func reconcilePartitions(
+	ctx context.Context,
+	adminClient *kadm.Client,
+	logger *zap.Logger,
+	mode string,
+	topic kadm.TopicDetail,
+	partitions int32,
+	replicationFactor int16,
+) error {
+	if replicationFactor != -1 && len(topic.Partitions) > 0 {
+		currentReplicationFactor := topic.Partitions.NumReplicas()
+		if currentReplicationFactor != int(replicationFactor) {
+			logger.Warn("topic replication factor differs from the configured replication factor, it must be changed manually",
+				zap.Int("current", currentReplicationFactor),
+				zap.Int16("desired", replicationFactor))
+		}
+	}
+
+	currentPartitions := len(topic.Partitions)
+	switch diffPartitions(mode, currentPartitions, partitions) {
+	case partitionDiffNone:
+		return nil
+	case partitionDiffTooMany:
+		logger.Warn("topic has more partitions than configured, partitions can not be removed",
+			zap.Int("current", currentPartitions),
+			zap.Int32("desired", partitions))
+		return nil
+	case partitionDiffTooFew:
+		logger.Warn("topic has less partitions than configured",
+			zap.Int("current", currentPartitions),
+			zap.Int32("desired", partitions))
+		return nil
+	}
+
+	responses, err := adminClient.UpdatePartitions(ctx, int(partitions), topic.Topic)
+	if err != nil {
+		return fmt.Errorf("failed to add partitions: %w", err)
+	}
+	for _, res := range responses {
+		if res.Err != nil {
+			return fmt.Errorf("failed to add partitions: %w", res.Err)
+		}
+	}
+	logger.Info("added partitions to topic",
+		zap.Int("previous", currentPartitions),
+		zap.Int32("current", partitions))
+
+	return nil
+}
+
+// partitionDiff is the difference between the current and the configured
+// partition count of a topic.
+type partitionDiff int
+
+const (
+	partitionDiffNone partitionDiff = iota
+	// partitionDiffTooMany means the topic has more partitions than configured.
+	partitionDiffTooMany
+	// partitionDiffTooFew means the topic has less partitions than configured,
+	// but partitions must not be added in the given reconcile mode.
+	partitionDiffTooFew
+	// partitionDiffAdd means partitions must be added to the topic.
+	partitionDiffAdd
+)
+
+// diffPartitions compares the current with the desired partition count. A
+// desired partition count of -1 refers to the broker's default and is
+// therefore not compared.
+func diffPartitions(mode string, current int, desired int32) partitionDiff {
+	switch {
+	case desired == -1 || current == int(desired):
+		return partitionDiffNone
+	case current > int(desired):
+		return partitionDiffTooMany
+	case mode == config.ReconcileModeWarn:
+		return partitionDiffTooFew
+	}
+
+	return partitionDiffAdd
+}
+
+// reconcileConfigs compares the given configs with the configs of an existing topic.
+// Configs that are not part of the given configs are not compared.
+func reconcileConfigs(
+	ctx context.Context,
+	adminClient *kadm.Client,
+	logger *zap.Logger,
+	mode string,
+	topicName string,
+	configs map[string]*string,
+) error {
+	if len(configs) == 0 {
+		return nil
+	}
+
+	resourceConfigs, err := adminClient.DescribeTopicConfigs(ctx, topicName)
+	if err != nil {
+		return fmt.Errorf("failed to describe topic configs: %w", err)
+	}
+	resourceConfig, err := resourceConfigs.On(topicName, nil)
+	if err != nil {
+		return fmt.Errorf("failed to describe topic configs: %w", err)
+	}
+	if resourceConfig.Err != nil {
+		return fmt.Errorf("failed to describe topic configs: %w", resourceConfig.Err)
+	}
+	currentConfigs := make(map[string]string, len(resourceConfig.Configs))
+	for _, cfg := range resourceConfig.Configs {
+		if cfg.Value != nil {
+			currentConfigs[cfg.Key] = *cfg.Value
+		}
+	}
+
+	alterConfigs := diffConfigs(currentConfigs, configs)
+	for _, alterConfig := range alterConfigs {
+		logger.Warn("topic config differs from the configured value",
+			zap.String("config", alterConfig.Name),
+			zap.String("current", currentConfigs[alterConfig.Name]),
+			zap.String("desired", *alterConfig.Value))
+	}
+	if len(alterConfigs) == 0 || mode != config.ReconcileModeEnforce {
+		return nil
 	}
 
-	// TODO: Check if Kafka topic config matches
+	responses, err := adminClient.AlterTopicConfigs(ctx, alterConfigs, topicName)
+	if err != nil {
+		return fmt.Errorf("failed to alter topic configs: %w", err)
+	}
+	for _, res := range responses {
+		if res.Err != nil {
+			return fmt.Errorf("failed to alter topic configs: %w", res.Err)
+		}
+	}
+	logger.Info("altered topic configs", zap.Int("altered_configs", len(alterConfigs)))
+
 	return nil
 }
+
+// diffConfigs returns the configs that must be set, so that the current configs
+// match the desired configs. Desired configs without a value are skipped. The
+// configs are sorted by name.
+func diffConfigs(current map[string]string, desired map[string]*string) []kadm.AlterConfig {
+	var alterConfigs []kadm.AlterConfig
+	for name, value := range desired {
+		if value == nil {
+			continue
+		}
+		if currentValue, exists := current[name]; exists && currentValue == *value {
+			continue
+		}
+		alterConfigs = append(alterConfigs, kadm.AlterConfig{Op: kadm.SetConfig, Name: name, Value: value})
+	}
+	sort.Slice(alterConfigs, func(i, j int) bool {
+		return alterConfigs[i].Name < alterConfigs[j].Name
+	})
+
+	return alterConfigs
+}
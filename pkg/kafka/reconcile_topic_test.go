@@ -0,0 +1,72 @@
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+func TestDiffPartitions(t *testing.T) {
+	tests := []struct {
+		name    string
+		mode    string
+		current int
+		desired int32
+		want    partitionDiff
+	}{
+		{name: "broker default", mode: config.ReconcileModeEnforce, current: 3, desired: -1, want: partitionDiffNone},
+		{name: "equal", mode: config.ReconcileModeEnforce, current: 3, desired: 3, want: partitionDiffNone},
+		{name: "too many", mode: config.ReconcileModeEnforce, current: 6, desired: 3, want: partitionDiffTooMany},
+		{name: "too few in warn mode", mode: config.ReconcileModeWarn, current: 3, desired: 6, want: partitionDiffTooFew},
+		{name: "too few in enforce mode", mode: config.ReconcileModeEnforce, current: 3, desired: 6, want: partitionDiffAdd},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffPartitions(tt.mode, tt.current, tt.desired); got != tt.want {
+				t.Errorf("diffPartitions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	tests := []struct {
+		name    string
+		current map[string]string
+		desired map[string]*string
+		want    []kadm.AlterConfig
+	}{
+		{
+			name:    "equal configs",
+			current: map[string]string{"cleanup.policy": "compact", "retention.ms": "1000"},
+			desired: map[string]*string{"cleanup.policy": kadm.StringPtr("compact")},
+		},
+		{
+			name:    "changed and missing configs",
+			current: map[string]string{"cleanup.policy": "delete"},
+			desired: map[string]*string{
+				"retention.ms":   kadm.StringPtr("1000"),
+				"cleanup.policy": kadm.StringPtr("compact"),
+			},
+			want: []kadm.AlterConfig{
+				{Op: kadm.SetConfig, Name: "cleanup.policy", Value: kadm.StringPtr("compact")},
+				{Op: kadm.SetConfig, Name: "retention.ms", Value: kadm.StringPtr("1000")},
+			},
+		},
+		{
+			name:    "configs without value",
+			current: map[string]string{"cleanup.policy": "delete"},
+			desired: map[string]*string{"cleanup.policy": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := diffConfigs(tt.current, tt.desired); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("diffConfigs() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
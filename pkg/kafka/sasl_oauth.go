@@ -0,0 +1,133 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/sasl/oauth"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+const (
+	// oauthTokenExpiryLeeway is subtracted from the lifetime of requested tokens,
+	// so that a token doesn't expire while a connection is being authenticated.
+	// Tokens with a short lifetime are renewed after half of their lifetime instead.
+	oauthTokenExpiryLeeway = 30 * time.Second
+	// oauthDefaultTokenLifetime is assumed if the token endpoint doesn't return
+	// the lifetime of a token, which is optional in RFC 6749.
+	oauthDefaultTokenLifetime = 5 * time.Minute
+)
+
+// newOAuthFn returns the function that provides the token for every
+// OAUTHBEARER authentication, based on the configured token source.
+func newOAuthFn(cfg config.SASLOAuthConfig) func(context.Context) (oauth.Auth, error) {
+	var tokenFn func(context.Context) (string, error)
+	switch cfg.TokenSource {
+	case config.SASLOAuthTokenSourceFile:
+		tokenFn = func(context.Context) (string, error) {
+			token, err := os.ReadFile(cfg.TokenFilepath)
+			if err != nil {
+				return "", fmt.Errorf("failed to read token file: %w", err)
+			}
+			return strings.TrimSpace(string(token)), nil
+		}
+	case config.SASLOAuthTokenSourceClientCredentials:
+		tokenFn = (&clientCredentialsTokenSource{
+			cfg:        cfg,
+			httpClient: &http.Client{Timeout: 10 * time.Second},
+		}).Token
+	default:
+		tokenFn = func(context.Context) (string, error) {
+			return cfg.Token, nil
+		}
+	}
+
+	return func(ctx context.Context) (oauth.Auth, error) {
+		token, err := tokenFn(ctx)
+		if err != nil {
+			return oauth.Auth{}, err
+		}
+		return oauth.Auth{Token: token, Extensions: cfg.Extensions}, nil
+	}
+}
+
+// clientCredentialsTokenSource requests tokens from an OAuth2 token endpoint
+// using the client credentials grant. Tokens are cached until shortly before
+// they expire.
+type clientCredentialsTokenSource struct {
+	cfg        config.SASLOAuthConfig
+	httpClient *http.Client
+
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+// tokenResponse is the successful response of a token endpoint as defined in RFC 6749.
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Token returns a cached token or requests a new one if it has expired.
+func (s *clientCredentialsTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Before(s.expiry) {
+		return s.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(s.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.cfg.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.cfg.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.cfg.ClientID), url.QueryEscape(s.cfg.ClientSecret))
+
+	res, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to request token: %w", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token response: %w", err)
+	}
+	if res.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint responded with status %v: %v", res.StatusCode, string(body))
+	}
+
+	var tokenRes tokenResponse
+	if err := json.Unmarshal(body, &tokenRes); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tokenRes.AccessToken == "" {
+		return "", fmt.Errorf("token response does not contain an access token")
+	}
+
+	lifetime := time.Duration(tokenRes.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = oauthDefaultTokenLifetime
+	}
+	s.token = tokenRes.AccessToken
+	s.expiry = time.Now().Add(lifetime - min(oauthTokenExpiryLeeway, lifetime/2))
+
+	return s.token, nil
+}
@@ -0,0 +1,127 @@
+package kafka
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+// newTestTokenSource returns a token source that requests tokens from a mock
+// token endpoint, which responds with the given status code and body. The
+// returned counter is incremented for each token request.
+func newTestTokenSource(t *testing.T, statusCode int, body string) (*clientCredentialsTokenSource, *atomic.Int32) {
+	t.Helper()
+
+	requests := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "owl-shop" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("grant_type") != "client_credentials" || r.PostForm.Get("scope") != "kafka produce" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+
+	return &clientCredentialsTokenSource{
+		cfg: config.SASLOAuthConfig{
+			TokenSource:   config.SASLOAuthTokenSourceClientCredentials,
+			TokenEndpoint: server.URL,
+			ClientID:      "owl-shop",
+			ClientSecret:  "secret",
+			Scopes:        []string{"kafka", "produce"},
+		},
+		httpClient: server.Client(),
+	}, requests
+}
+
+func TestClientCredentialsTokenSourceCachesToken(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "with lifetime", body: `{"access_token":"token","token_type":"Bearer","expires_in":3600}`},
+		{name: "short lifetime", body: `{"access_token":"token","token_type":"Bearer","expires_in":10}`},
+		{name: "without lifetime", body: `{"access_token":"token","token_type":"Bearer"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, requests := newTestTokenSource(t, http.StatusOK, tt.body)
+
+			for i := 0; i < 3; i++ {
+				token, err := source.Token(context.Background())
+				if err != nil {
+					t.Fatalf("Token() error = %v", err)
+				}
+				if token != "token" {
+					t.Errorf("Token() = %v, want token", token)
+				}
+			}
+			if got := requests.Load(); got != 1 {
+				t.Errorf("token endpoint has been called %d times, want 1", got)
+			}
+		})
+	}
+}
+
+func TestClientCredentialsTokenSourceErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		statusCode int
+		body       string
+		wantErr    string
+	}{
+		{
+			name:       "non-200 response",
+			statusCode: http.StatusBadRequest,
+			body:       `{"error":"invalid_client"}`,
+			wantErr:    "status 400",
+		},
+		{
+			name:       "missing access token",
+			statusCode: http.StatusOK,
+			body:       `{"token_type":"Bearer","expires_in":3600}`,
+			wantErr:    "does not contain an access token",
+		},
+		{
+			name:       "invalid json",
+			statusCode: http.StatusOK,
+			body:       `<html></html>`,
+			wantErr:    "failed to decode token response",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, requests := newTestTokenSource(t, tt.statusCode, tt.body)
+
+			// Failed requests must not be cached
+			for i := 0; i < 2; i++ {
+				_, err := source.Token(context.Background())
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Token() error = %v, want error containing %q", err, tt.wantErr)
+				}
+			}
+			if got := requests.Load(); got != 2 {
+				t.Errorf("token endpoint has been called %d times, want 2", got)
+			}
+		})
+	}
+}
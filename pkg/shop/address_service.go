@@ -7,13 +7,17 @@ import (
 	"sync"
 	"time"
 
-	"github.com/twmb/franz-go/pkg/kadm"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"go.uber.org/zap"
 
 	"github.com/cloudhut/owl-shop/pkg/config"
 	"github.com/cloudhut/owl-shop/pkg/fake"
 	"github.com/cloudhut/owl-shop/pkg/kafka"
+	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
+	embedavro "github.com/cloudhut/owl-shop/pkg/shop/schemas/avro"
+	embedjson "github.com/cloudhut/owl-shop/pkg/shop/schemas/json"
+	"github.com/cloudhut/owl-shop/pkg/sink"
+	embedproto "github.com/cloudhut/owl-shop/proto"
 )
 
 // AddressService consumes the customers topic to collect customer ID and name
@@ -22,8 +26,14 @@ type AddressService struct {
 	cfg          config.Shop
 	logger       *zap.Logger
 	kafkaFactory *kafka.Factory
+	generator    *fake.Generator
 
-	metaClient     *kgo.Client
+	sink sink.Sink
+	keys *recordKeys
+	// schemaTopics is nil if schema registry hasn't been configured
+	schemaTopics *schemaTopics
+	// consumerClient is nil if records are not written to Kafka. In this case
+	// customers must be passed to HandleCustomerRecord by the caller.
 	consumerClient *kgo.Client
 
 	bufferSize       int
@@ -35,47 +45,85 @@ type AddressService struct {
 }
 
 // NewAddressService creates the service that publishes addresses to the
-// address topic.
+// address topics. The Kafka factory is only used for consuming customers and
+// may be nil if records are not written to Kafka. The schema registry client
+// is optional and may be nil, in which case addresses are only produced as JSON.
+// It is required if keys are encoded with a schema registry format.
 func NewAddressService(
 	cfg config.Shop,
 	logger *zap.Logger,
 	kafkaFactory *kafka.Factory,
+	sinkFactory sink.Factory,
+	generator *fake.Generator,
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
 ) (*AddressService, error) {
 	clientID := cfg.GlobalPrefix + "address-service"
-	topicName := cfg.GlobalPrefix + "addresses"
-	consumerClient, err := kafkaFactory.NewKafkaClient(
-		clientID,
-		kgo.ConsumeTopics(topicName),
-		kgo.ConsumerGroup(clientID),
-		kgo.AutoCommitInterval(500*time.Millisecond),
-	)
+
+	keys, err := newRecordKeys(cfg.Keys, srClient, subjects, "Address", &shoppb.AddressKey{}, embedavro.AddressKeyAvro)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create consumer client: %w", err)
+		return nil, err
 	}
 
-	metaClient, err := kafkaFactory.NewKafkaClient(clientID)
+	var consumerClient *kgo.Client
+	if kafkaFactory != nil {
+		consumerClient, err = kafkaFactory.NewKafkaClient(
+			clientID,
+			kgo.ConsumeTopics(cfg.Topic(config.ShopTopicCustomers).Name),
+			kgo.ConsumerGroup(clientID),
+			kgo.AutoCommitInterval(500*time.Millisecond),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create consumer client: %w", err)
+		}
+	}
+
+	recordSink, err := sinkFactory.NewSink(clientID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create meta client: %w", err)
+		return nil, fmt.Errorf("failed to create sink: %w", err)
 	}
 
 	// This slice is used to keep some customers in the buffer so that we can produce addresses for these customers
 	bufferSize := 500
 	recentCustomers := make([]fake.Customer, 0, bufferSize)
 
+	var addressSchemaTopics *schemaTopics
+	if srClient != nil {
+		topicCfg := map[string]string{"cleanup.policy": "compact"}
+		avroTopic := newSinkTopic(cfg, config.ShopTopicAddressesAvroSr, sink.FormatAvroSR, topicCfg)
+		avroTopic.Schema = embedavro.AddressAvro
+		jsonTopic := newSinkTopic(cfg, config.ShopTopicAddressesJsonSr, sink.FormatJSONSR, topicCfg)
+		jsonTopic.Schema = embedjson.AddressJSON
+		addressSchemaTopics = newSchemaTopics(
+			srClient,
+			subjects,
+			keys,
+			"Address",
+			newSinkTopic(cfg, config.ShopTopicAddressesProtobufSr, sink.FormatProtobufSR, topicCfg),
+			embedproto.Address,
+			&shoppb.Address{},
+			avroTopic,
+			jsonTopic,
+		)
+	}
+
 	return &AddressService{
 		cfg:          cfg,
 		logger:       logger.With(zap.String("service", "address_service")),
 		kafkaFactory: kafkaFactory,
+		generator:    generator,
 
 		consumerClient: consumerClient,
-		metaClient:     metaClient,
+		sink:           recordSink,
+		keys:           keys,
+		schemaTopics:   addressSchemaTopics,
 
 		bufferSize:       bufferSize,
 		recentCustomerMu: sync.RWMutex{},
 		recentCustomers:  recentCustomers,
 
 		clientID:  clientID,
-		topicName: cfg.GlobalPrefix + "addresses",
+		topicName: cfg.Topic(config.ShopTopicAddresses).Name,
 	}, nil
 }
 
@@ -83,27 +131,36 @@ func NewAddressService(
 func (svc *AddressService) Initialize(ctx context.Context) error {
 	svc.logger.Info("initializing address service")
 
-	err := kafka.ReconcileTopic(ctx,
-		svc.metaClient,
-		svc.topicName,
-		svc.cfg.TopicPartitionCount,
-		svc.cfg.TopicReplicationFactor,
-		map[string]*string{
-			"cleanup.policy": kadm.StringPtr("compact"),
-		},
-	)
+	err := svc.keys.CreateTopic(ctx, svc.sink, newSinkTopic(svc.cfg, config.ShopTopicAddresses, sink.FormatJSON, map[string]string{
+		"cleanup.policy": "compact",
+	}))
 	if err != nil {
 		return fmt.Errorf("failed to reconcile topic: %w", err)
 	}
 
+	if svc.schemaTopics != nil {
+		if err := svc.schemaTopics.Initialize(ctx, svc.sink, fake.Address{}); err != nil {
+			return fmt.Errorf("failed to initialize schema registry topics: %w", err)
+		}
+	}
+
 	return nil
 }
 
 // Start consuming messages from customers topic that are required
-// to produce address records.
-func (svc *AddressService) Start() {
+// to produce address records. It returns once the given context is
+// cancelled or immediately if there is no Kafka consumer.
+func (svc *AddressService) Start(ctx context.Context) {
+	if svc.consumerClient == nil {
+		return
+	}
+
 	for {
-		fetches := svc.consumerClient.PollFetches(context.Background())
+		fetches := svc.consumerClient.PollFetches(ctx)
+
+		if ctx.Err() != nil {
+			return
+		}
 
 		if fetches.IsClientClosed() {
 			svc.logger.Warn("client closed")
@@ -117,70 +174,116 @@ func (svc *AddressService) Start() {
 				zap.Error(err))
 		})
 
-		fetches.EachRecord(func(rec *kgo.Record) {
-			kafkaMessagesConsumedTotal.
-				With(map[string]string{"event_type": EventTypeCustomerConsumed}).
-				Inc()
+		fetches.EachRecord(svc.HandleCustomerRecord)
+	}
+}
 
-			if rec.Value == nil {
-				return
-			}
+// HandleCustomerRecord adds the customer of a record from the customers topic
+// to the buffer, so that addresses can be created for that customer.
+func (svc *AddressService) HandleCustomerRecord(rec *kgo.Record) {
+	kafkaMessagesConsumedTotal.
+		With(map[string]string{"event_type": EventTypeCustomerConsumed}).
+		Inc()
 
-			customer := fake.Customer{}
-			err := json.Unmarshal(rec.Value, &customer)
-			if err != nil {
-				// Skip message
-				svc.logger.Warn("failed to deserialize customer", zap.Error(err))
-				return
-			}
-			svc.recentCustomerMu.Lock()
-			if len(svc.recentCustomers) < svc.bufferSize {
-				svc.recentCustomers = append(svc.recentCustomers, customer)
-			}
-			svc.recentCustomerMu.Unlock()
-		})
+	if rec.Value == nil {
+		return
 	}
+
+	customer := fake.Customer{}
+	err := json.Unmarshal(rec.Value, &customer)
+	if err != nil {
+		// Skip message
+		svc.logger.Warn("failed to deserialize customer", zap.Error(err))
+		return
+	}
+	svc.recentCustomerMu.Lock()
+	if len(svc.recentCustomers) < svc.bufferSize {
+		svc.recentCustomers = append(svc.recentCustomers, customer)
+	}
+	svc.recentCustomerMu.Unlock()
 }
 
 // CreateAddress produces a new fake address record and produces that record
 // to the address topic.
-func (svc *AddressService) CreateAddress() {
+func (svc *AddressService) CreateAddress() error {
 	customer, err := svc.popCustomerFromBuffer()
 	if err != nil {
 		svc.logger.Debug("failed to pop customer from buffer", zap.Error(err))
-		return
+		return err
 	}
-	address := fake.NewAddress(customer)
-	err = svc.produceAddress(address)
+	address := svc.generator.NewAddress(customer)
+	produced, err := svc.produceAddress(address)
 	if err != nil {
 		svc.logger.Warn("failed to produce address", zap.Error(err))
-		return
+		return err
 	}
-	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeAddressCreated}).Inc()
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeAddressCreated}).Add(float64(produced))
+
+	return nil
 }
 
-func (svc *AddressService) produceAddress(address fake.Address) error {
+// produceAddress produces the address to all address topics and returns the
+// number of produced records.
+func (svc *AddressService) produceAddress(address fake.Address) (int, error) {
 	serialized, err := json.Marshal(address)
 	if err != nil {
-		return fmt.Errorf("failed to serialize customer struct: %w", err)
+		return 0, fmt.Errorf("failed to serialize customer struct: %w", err)
 	}
 
-	rec := kgo.Record{
-		Key:     []byte(address.ID),
-		Value:   serialized,
-		Headers: []kgo.RecordHeader{{Key: "revision", Value: []byte("0")}},
-		Topic:   svc.topicName,
+	key, err := svc.keys.Encode(svc.topicName, address.ID)
+	if err != nil {
+		return 0, err
 	}
 
-	svc.metaClient.Produce(context.Background(), &rec, func(rec *kgo.Record, err error) {
-		if err == nil {
-			return
+	headers := []kgo.RecordHeader{{Key: "revision", Value: []byte("0")}}
+	records := []*kgo.Record{{
+		Key:       key,
+		Value:     serialized,
+		Headers:   headers,
+		Timestamp: address.CreatedAt,
+		Topic:     svc.topicName,
+	}}
+	if svc.schemaTopics != nil {
+		srRecords, err := svc.schemaTopics.Records(address.ID, address.CreatedAt, headers, address.Protobuf(), address)
+		if err != nil {
+			return 0, err
 		}
-		svc.logger.Error("failed to produce record",
-			zap.String("topic_name", rec.Topic),
-			zap.Error(err),
-		)
-	})
+		records = append(records, srRecords...)
+	}
+
+	for _, rec := range records {
+		svc.sink.Produce(context.Background(), rec, func(rec *kgo.Record, err error) {
+			if err == nil {
+				return
+			}
+			svc.logger.Error("failed to produce record",
+				zap.String("topic_name", rec.Topic),
+				zap.Error(err),
+			)
+		})
+	}
+
+	return len(records), nil
+}
+
+// Close commits the consumed offsets, flushes all buffered address records and
+// closes the Kafka consumer and the sink. Start must have returned before calling Close.
+func (svc *AddressService) Close(ctx context.Context) error {
+	defer svc.sink.Close()
+	if svc.consumerClient != nil {
+		defer svc.consumerClient.Close()
+	}
+
+	if err := svc.sink.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush buffered records: %w", err)
+	}
+
+	if svc.consumerClient != nil {
+		if err := svc.consumerClient.CommitUncommittedOffsets(ctx); err != nil {
+			return fmt.Errorf("failed to commit offsets: %w", err)
+		}
+	}
+
 	return nil
 }
 
@@ -190,7 +293,7 @@ func (svc *AddressService) popCustomerFromBuffer() (fake.Customer, error) {
 
 	if len(svc.recentCustomers) == 0 {
 		// No customers in buffer yet
-		return fake.Customer{}, fmt.Errorf("buffer is empty")
+		return fake.Customer{}, errBufferEmpty
 	}
 	customer := svc.recentCustomers[0]
 	svc.recentCustomers = svc.recentCustomers[1:]
@@ -0,0 +1,161 @@
+package shop
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+// maxTriggerCount is the maximum number of actions that can be triggered with
+// a single request to the control API.
+const maxTriggerCount = 10000
+
+// RateResponse is the response of the rate endpoints.
+type RateResponse struct {
+	RequestRate int    `json:"requestRate"`
+	Interval    string `json:"interval"`
+	Paused      bool   `json:"paused"`
+}
+
+// RateRequest is the request body for changing the request rate at runtime.
+// Fields that are not set remain unchanged.
+type RateRequest struct {
+	RequestRate *int    `json:"requestRate"`
+	Interval    *string `json:"interval"`
+}
+
+// TriggerResponse is the response of the trigger endpoint.
+type TriggerResponse struct {
+	Action   string `json:"action"`
+	Count    int    `json:"count"`
+	Executed int    `json:"executed"`
+}
+
+// registerControlAPI registers all handlers of the control API, which allows
+// to change the traffic simulation of a running shop.
+func (s *Shop) registerControlAPI(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/rate", s.handleGetRate)
+	mux.HandleFunc("PUT /api/v1/rate", s.handlePutRate)
+	mux.HandleFunc("GET /api/v1/mix", s.handleGetMix)
+	mux.HandleFunc("PUT /api/v1/mix", s.handlePutMix)
+	mux.HandleFunc("POST /api/v1/pause", s.handlePause)
+	mux.HandleFunc("POST /api/v1/resume", s.handleResume)
+	mux.HandleFunc("POST /api/v1/trigger/{action}", s.handleTrigger)
+}
+
+func (s *Shop) handleGetRate(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.rateResponse())
+}
+
+func (s *Shop) handlePutRate(w http.ResponseWriter, r *http.Request) {
+	var req RateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("failed to decode request body: %w", err))
+		return
+	}
+
+	requestRate, interval := s.Rate()
+	if req.RequestRate != nil {
+		requestRate = *req.RequestRate
+	}
+	if req.Interval != nil {
+		parsed, err := time.ParseDuration(*req.Interval)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, fmt.Errorf("failed to parse interval: %w", err))
+			return
+		}
+		interval = parsed
+	}
+
+	if err := s.SetRate(requestRate, interval); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, s.rateResponse())
+}
+
+func (s *Shop) handleGetMix(w http.ResponseWriter, _ *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.EventMix())
+}
+
+func (s *Shop) handlePutMix(w http.ResponseWriter, r *http.Request) {
+	var req config.ShopEventMix
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.writeError(w, http.StatusBadRequest, fmt.Errorf("failed to decode request body: %w", err))
+		return
+	}
+
+	// Actions that are not part of the request keep their current weight
+	eventMix := s.EventMix()
+	for action, weight := range req.Normalized() {
+		eventMix[action] = weight
+	}
+
+	if err := s.SetEventMix(eventMix); err != nil {
+		s.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, s.EventMix())
+}
+
+func (s *Shop) handlePause(w http.ResponseWriter, _ *http.Request) {
+	s.Pause()
+	s.writeJSON(w, http.StatusOK, s.rateResponse())
+}
+
+func (s *Shop) handleResume(w http.ResponseWriter, _ *http.Request) {
+	s.Resume()
+	s.writeJSON(w, http.StatusOK, s.rateResponse())
+}
+
+func (s *Shop) handleTrigger(w http.ResponseWriter, r *http.Request) {
+	action := r.PathValue("action")
+
+	count := 1
+	if countStr := r.URL.Query().Get("count"); countStr != "" {
+		parsed, err := strconv.Atoi(countStr)
+		if err != nil || parsed <= 0 || parsed > maxTriggerCount {
+			s.writeError(w, http.StatusBadRequest,
+				fmt.Errorf("count must be an integer between 1 and %d", maxTriggerCount))
+			return
+		}
+		count = parsed
+	}
+
+	executed, err := s.TriggerAction(action, count)
+	if err != nil {
+		s.writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, TriggerResponse{Action: action, Count: count, Executed: executed})
+}
+
+func (s *Shop) rateResponse() RateResponse {
+	requestRate, interval := s.Rate()
+	return RateResponse{
+		RequestRate: requestRate,
+		Interval:    interval.String(),
+		Paused:      s.paused.Load(),
+	}
+}
+
+func (s *Shop) writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		s.logger.Warn("failed to write http response", zap.Error(err))
+	}
+}
+
+func (s *Shop) writeError(w http.ResponseWriter, statusCode int, err error) {
+	s.writeJSON(w, statusCode, map[string]string{"message": err.Error()})
+}
@@ -0,0 +1,238 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+// newTestControlAPI creates an initialized shop that writes to files and
+// returns the handler of its control API.
+func newTestControlAPI(t *testing.T) (*Shop, http.Handler) {
+	t.Helper()
+
+	var cfg config.Config
+	cfg.SetDefaults()
+	cfg.Sink.Type = config.SinkTypeFile
+	cfg.Sink.File.Directory = t.TempDir()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("failed to validate config: %v", err)
+	}
+
+	shopSvc, err := New(cfg, zap.NewNop())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := shopSvc.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+	t.Cleanup(func() { _ = shopSvc.Close(context.Background()) })
+
+	mux := http.NewServeMux()
+	shopSvc.registerControlAPI(mux)
+	return shopSvc, mux
+}
+
+// serveJSON sends the request to the handler and decodes the JSON response
+// into v, unless the response is an error.
+func serveJSON(t *testing.T, handler http.Handler, method, target, body string, v any) int {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		var errResponse map[string]string
+		if err := json.Unmarshal(rec.Body.Bytes(), &errResponse); err != nil || errResponse["message"] == "" {
+			t.Errorf("error response %q has no message", rec.Body.String())
+		}
+		return rec.Code
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return rec.Code
+}
+
+func TestControlAPIRate(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		want       RateResponse
+	}{
+		{name: "get", method: http.MethodGet, wantStatus: http.StatusOK, want: RateResponse{RequestRate: 2, Interval: "1s"}},
+		{
+			name:       "set request rate",
+			method:     http.MethodPut,
+			body:       `{"requestRate": 50}`,
+			wantStatus: http.StatusOK,
+			want:       RateResponse{RequestRate: 50, Interval: "1s"},
+		},
+		{
+			name:       "set interval",
+			method:     http.MethodPut,
+			body:       `{"interval": "2s"}`,
+			wantStatus: http.StatusOK,
+			want:       RateResponse{RequestRate: 2, Interval: "2s"},
+		},
+		{name: "invalid body", method: http.MethodPut, body: `{"requestRate":`, wantStatus: http.StatusBadRequest},
+		{name: "zero request rate", method: http.MethodPut, body: `{"requestRate": 0}`, wantStatus: http.StatusBadRequest},
+		{name: "unparsable interval", method: http.MethodPut, body: `{"interval": "often"}`, wantStatus: http.StatusBadRequest},
+		{name: "negative interval", method: http.MethodPut, body: `{"interval": "-1s"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shopSvc, handler := newTestControlAPI(t)
+
+			var got RateResponse
+			status := serveJSON(t, handler, tt.method, "/api/v1/rate", tt.body, &got)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %v, want %v", status, tt.wantStatus)
+			}
+			if status != http.StatusOK {
+				if requestRate, interval := shopSvc.Rate(); requestRate != 2 || interval.String() != "1s" {
+					t.Errorf("rejected request changed the rate to %v per %v", requestRate, interval)
+				}
+				return
+			}
+			if got != tt.want {
+				t.Errorf("response = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestControlAPIMix(t *testing.T) {
+	allDisabled := make(config.ShopEventMix)
+	for _, action := range config.ShopActions() {
+		allDisabled[action] = 0
+	}
+	allDisabledBody, err := json.Marshal(allDisabled)
+	if err != nil {
+		t.Fatalf("failed to serialize event mix: %v", err)
+	}
+
+	var defaults config.ShopEventMix
+	defaults.SetDefaults()
+	withCreateOrder := make(config.ShopEventMix, len(defaults))
+	for action, weight := range defaults {
+		withCreateOrder[action] = weight
+	}
+	withCreateOrder[config.ShopActionCreateOrder] = 50
+
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		want       config.ShopEventMix
+	}{
+		{name: "get", method: http.MethodGet, wantStatus: http.StatusOK, want: defaults},
+		{
+			name:       "other actions keep their weight",
+			method:     http.MethodPut,
+			body:       `{"createorder": 50}`,
+			wantStatus: http.StatusOK,
+			want:       withCreateOrder,
+		},
+		{name: "invalid body", method: http.MethodPut, body: `[]`, wantStatus: http.StatusBadRequest},
+		{name: "unknown action", method: http.MethodPut, body: `{"createInvoice": 1}`, wantStatus: http.StatusBadRequest},
+		{name: "all disabled", method: http.MethodPut, body: string(allDisabledBody), wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shopSvc, handler := newTestControlAPI(t)
+
+			var got config.ShopEventMix
+			status := serveJSON(t, handler, tt.method, "/api/v1/mix", tt.body, &got)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %v, want %v", status, tt.wantStatus)
+			}
+			if status != http.StatusOK {
+				if !reflect.DeepEqual(shopSvc.EventMix(), defaults) {
+					t.Errorf("rejected request changed the event mix to %v", shopSvc.EventMix())
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("response = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestControlAPIPauseResume(t *testing.T) {
+	_, handler := newTestControlAPI(t)
+
+	steps := []struct {
+		method     string
+		target     string
+		wantPaused bool
+	}{
+		{method: http.MethodPost, target: "/api/v1/pause", wantPaused: true},
+		{method: http.MethodGet, target: "/api/v1/rate", wantPaused: true},
+		// Pausing a paused shop has no effect
+		{method: http.MethodPost, target: "/api/v1/pause", wantPaused: true},
+		{method: http.MethodPost, target: "/api/v1/resume", wantPaused: false},
+		{method: http.MethodGet, target: "/api/v1/rate", wantPaused: false},
+	}
+	for _, step := range steps {
+		var got RateResponse
+		if status := serveJSON(t, handler, step.method, step.target, "", &got); status != http.StatusOK {
+			t.Fatalf("%v %v: status = %v, want %v", step.method, step.target, status, http.StatusOK)
+		}
+		if got.Paused != step.wantPaused {
+			t.Errorf("%v %v: paused = %v, want %v", step.method, step.target, got.Paused, step.wantPaused)
+		}
+	}
+}
+
+func TestControlAPITrigger(t *testing.T) {
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		want       TriggerResponse
+	}{
+		{
+			name:       "default count",
+			target:     "/api/v1/trigger/createCustomer",
+			wantStatus: http.StatusOK,
+			want:       TriggerResponse{Action: config.ShopActionCreateCustomer, Count: 1, Executed: 1},
+		},
+		{
+			name:       "count",
+			target:     "/api/v1/trigger/createCustomer?count=3",
+			wantStatus: http.StatusOK,
+			want:       TriggerResponse{Action: config.ShopActionCreateCustomer, Count: 3, Executed: 3},
+		},
+		{name: "zero count", target: "/api/v1/trigger/createCustomer?count=0", wantStatus: http.StatusBadRequest},
+		{name: "negative count", target: "/api/v1/trigger/createCustomer?count=-1", wantStatus: http.StatusBadRequest},
+		{name: "count above max", target: "/api/v1/trigger/createCustomer?count=10001", wantStatus: http.StatusBadRequest},
+		{name: "count is no integer", target: "/api/v1/trigger/createCustomer?count=many", wantStatus: http.StatusBadRequest},
+		{name: "unknown action", target: "/api/v1/trigger/createInvoice", wantStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, handler := newTestControlAPI(t)
+
+			var got TriggerResponse
+			status := serveJSON(t, handler, http.MethodPost, tt.target, "", &got)
+			if status != tt.wantStatus {
+				t.Fatalf("status = %v, want %v", status, tt.wantStatus)
+			}
+			if status == http.StatusOK && got != tt.want {
+				t.Errorf("response = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
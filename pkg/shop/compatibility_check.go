@@ -0,0 +1,331 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	franzsr "github.com/twmb/franz-go/pkg/sr"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/sr"
+)
+
+// incompatibleChange is a change of the customer schema that breaks compatibility
+// in at least one direction.
+type incompatibleChange struct {
+	description string
+	schemaType  franzsr.SchemaType
+	// breaksBackward is true if consumers with the changed schema can't read
+	// records of the previous schema.
+	breaksBackward bool
+	// breaksForward is true if consumers with the previous schema can't read
+	// records of the changed schema.
+	breaksForward bool
+	// schema applies the change to the latest registered customer schema.
+	schema func(latest string) (string, error)
+}
+
+// incompatibleChanges are attempted on the customer subject of each format. The
+// changes only affect fields that are part of all customer schema versions, so
+// that they can be applied to whichever version has been registered last by the
+// schema evolution.
+var incompatibleChanges = []incompatibleChange{
+	{
+		description:    "changed type of field last_name to int64",
+		schemaType:     franzsr.TypeProtobuf,
+		breaksBackward: true,
+		breaksForward:  true,
+		schema: func(latest string) (string, error) {
+			return replaceOnce(latest, "string last_name = 4;", "int64 last_name = 4;")
+		},
+	},
+	{
+		description:    "added required field referralCode without default",
+		schemaType:     franzsr.TypeAvro,
+		breaksBackward: true,
+		schema: func(latest string) (string, error) {
+			return changeAvroFields(latest, func(fields []map[string]any) []map[string]any {
+				return append(fields, map[string]any{"name": "referralCode", "type": "string"})
+			})
+		},
+	},
+	{
+		description:   "removed field lastName without default",
+		schemaType:    franzsr.TypeAvro,
+		breaksForward: true,
+		schema: func(latest string) (string, error) {
+			return changeAvroFields(latest, func(fields []map[string]any) []map[string]any {
+				var changed []map[string]any
+				for _, field := range fields {
+					if field["name"] != "lastName" {
+						changed = append(changed, field)
+					}
+				}
+				return changed
+			})
+		},
+	},
+	{
+		description:    "changed type of field lastName to int",
+		schemaType:     franzsr.TypeAvro,
+		breaksBackward: true,
+		breaksForward:  true,
+		schema: func(latest string) (string, error) {
+			return changeAvroFields(latest, func(fields []map[string]any) []map[string]any {
+				for _, field := range fields {
+					if field["name"] == "lastName" {
+						field["type"] = "int"
+					}
+				}
+				return fields
+			})
+		},
+	},
+	{
+		description:    "changed type of property lastName to integer",
+		schemaType:     franzsr.TypeJSON,
+		breaksBackward: true,
+		breaksForward:  true,
+		schema: func(latest string) (string, error) {
+			return replaceOnce(latest, "\"lastName\": {\n      \"type\": \"string\"", "\"lastName\": {\n      \"type\": \"integer\"")
+		},
+	},
+}
+
+func replaceOnce(schema, old, new string) (string, error) {
+	if strings.Count(schema, old) != 1 {
+		return "", fmt.Errorf("schema must contain '%v' exactly once", old)
+	}
+	return strings.Replace(schema, old, new, 1), nil
+}
+
+func changeAvroFields(schema string, change func(fields []map[string]any) []map[string]any) (string, error) {
+	var record struct {
+		Type      string           `json:"type"`
+		Name      string           `json:"name"`
+		Namespace string           `json:"namespace"`
+		Doc       string           `json:"doc"`
+		Fields    []map[string]any `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(schema), &record); err != nil {
+		return "", fmt.Errorf("failed to parse avro schema: %w", err)
+	}
+	record.Fields = change(record.Fields)
+
+	changed, err := json.Marshal(record)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize avro schema: %w", err)
+	}
+	return string(changed), nil
+}
+
+// CompatibilityVerdict is the registry's verdict on an incompatible registration.
+type CompatibilityVerdict struct {
+	Subject string
+	Change  string
+	// Level is the effective compatibility level of the subject.
+	Level string
+	// Expected is true if the level is expected to reject the change.
+	Expected bool
+	Rejected bool
+	// Reasons are the registry's messages for rejected registrations.
+	Reasons []string
+}
+
+// Matches returns true if the registry's verdict is the expected one.
+func (v CompatibilityVerdict) Matches() bool {
+	return v.Expected == v.Rejected
+}
+
+// CompatibilityReport lists the verdicts of all attempted registrations.
+type CompatibilityReport struct {
+	Verdicts []CompatibilityVerdict
+}
+
+// Mismatches returns the number of verdicts that differ from the expected verdict.
+func (r CompatibilityReport) Mismatches() int {
+	mismatches := 0
+	for _, verdict := range r.Verdicts {
+		if !verdict.Matches() {
+			mismatches++
+		}
+	}
+	return mismatches
+}
+
+// Print writes a human-readable table of all verdicts.
+func (r CompatibilityReport) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SUBJECT\tLEVEL\tCHANGE\tEXPECTED\tVERDICT\t")
+	for _, verdict := range r.Verdicts {
+		expected, actual := "accept", "accepted"
+		if verdict.Expected {
+			expected = "reject"
+		}
+		if verdict.Rejected {
+			actual = "rejected"
+		}
+		if !verdict.Matches() {
+			actual += " (unexpected)"
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\t%v\t\n", verdict.Subject, verdict.Level, verdict.Change, expected, actual)
+	}
+	_ = tw.Flush()
+
+	for _, verdict := range r.Verdicts {
+		if len(verdict.Reasons) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%v, %v:\n", verdict.Subject, verdict.Change)
+		for _, reason := range verdict.Reasons {
+			fmt.Fprintf(w, "  %v\n", reason)
+		}
+	}
+}
+
+// CompatibilityCheck deliberately attempts incompatible registrations of the
+// customer schemas to verify the compatibility levels of the shop's subjects.
+// Registrations that are accepted by the registry are deleted again, so that
+// the shop keeps producing with its own schemas.
+type CompatibilityCheck struct {
+	cfg    config.Config
+	logger *zap.Logger
+
+	srClient *franzsr.Client
+}
+
+// NewCompatibilityCheck creates the schema registry client that is required to
+// check the compatibility.
+func NewCompatibilityCheck(cfg config.Config, logger *zap.Logger) (*CompatibilityCheck, error) {
+	if cfg.Sink.Type != config.SinkTypeKafka {
+		return nil, fmt.Errorf("compatibility check requires the kafka sink, but sink type is '%v'", cfg.Sink.Type)
+	}
+
+	srClient, err := sr.NewFactory(cfg.SchemaRegistry, logger.Named("schema_registry")).NewSchemaRegistryClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema registry client: %w", err)
+	}
+	if srClient == nil {
+		return nil, fmt.Errorf("compatibility check requires a schema registry")
+	}
+
+	return &CompatibilityCheck{
+		cfg:      cfg,
+		logger:   logger,
+		srClient: srClient,
+	}, nil
+}
+
+// Run attempts all incompatible registrations on the customer subjects, which
+// must have been registered by initializing the shop before.
+func (c *CompatibilityCheck) Run(ctx context.Context) (CompatibilityReport, error) {
+	subjects := newSubjectNamer(c.cfg.SchemaRegistry)
+	customerSubjects := map[franzsr.SchemaType]string{
+		franzsr.TypeProtobuf: subjects.subject(
+			c.cfg.Shop.Topic(config.ShopTopicCustomersProtobufSr).Name, protobufRecordName("Customer")),
+		franzsr.TypeAvro: subjects.subject(
+			c.cfg.Shop.Topic(config.ShopTopicCustomersAvroSr).Name, avroRecordName("Customer")),
+		franzsr.TypeJSON: subjects.subject(
+			c.cfg.Shop.Topic(config.ShopTopicCustomersJsonSr).Name, jsonRecordName("Customer")),
+	}
+
+	var report CompatibilityReport
+	for _, change := range incompatibleChanges {
+		verdict, err := c.attempt(ctx, customerSubjects[change.schemaType], change)
+		if err != nil {
+			return CompatibilityReport{}, err
+		}
+		report.Verdicts = append(report.Verdicts, verdict)
+	}
+
+	return report, nil
+}
+
+// attempt registers the changed schema and returns the registry's verdict.
+func (c *CompatibilityCheck) attempt(ctx context.Context, subject string, change incompatibleChange) (CompatibilityVerdict, error) {
+	level, err := c.effectiveLevel(ctx, subject)
+	if err != nil {
+		return CompatibilityVerdict{}, err
+	}
+	verdict := CompatibilityVerdict{
+		Subject:  subject,
+		Change:   change.description,
+		Level:    level.String(),
+		Expected: breaksLevel(level, change),
+	}
+
+	// The registry checks the change against the latest version, which may have
+	// been registered by the schema evolution.
+	latest, err := c.srClient.SchemaByVersion(ctx, subject, -1)
+	if err != nil {
+		return CompatibilityVerdict{}, fmt.Errorf("failed to get latest schema of subject '%v': %w", subject, err)
+	}
+	schemaText, err := change.schema(latest.Schema.Schema)
+	if err != nil {
+		return CompatibilityVerdict{}, fmt.Errorf("failed to create changed schema '%v' of subject '%v' version %d: %w",
+			change.description, subject, latest.Version, err)
+	}
+	schema := franzsr.Schema{Schema: schemaText, Type: change.schemaType, References: latest.Schema.References}
+
+	registered, err := c.srClient.CreateSchema(ctx, subject, schema)
+	switch {
+	case err == nil:
+		c.logger.Info("registry accepted incompatible schema, deleting it again",
+			zap.String("subject", subject),
+			zap.String("change", change.description),
+			zap.Int("version", registered.Version))
+		if err := c.deleteVersion(ctx, subject, registered.Version); err != nil {
+			return CompatibilityVerdict{}, err
+		}
+	case sr.IsIncompatible(err):
+		verdict.Rejected = true
+		verdict.Reasons = sr.IncompatibilityReasons(ctx, c.srClient, subject, schema)
+	default:
+		return CompatibilityVerdict{}, fmt.Errorf("failed to register changed schema of subject '%v': %w", subject, err)
+	}
+
+	return verdict, nil
+}
+
+// effectiveLevel returns the compatibility level of the subject, or the global
+// level if the subject has none.
+func (c *CompatibilityCheck) effectiveLevel(ctx context.Context, subject string) (franzsr.CompatibilityLevel, error) {
+	results := c.srClient.Compatibility(franzsr.WithParams(ctx, franzsr.DefaultToGlobal), subject)
+	if results[0].Err != nil {
+		return 0, fmt.Errorf("failed to get compatibility of subject '%v': %w", subject, results[0].Err)
+	}
+
+	return results[0].Level, nil
+}
+
+// deleteVersion permanently deletes an accepted registration. Versions must be
+// soft deleted before they can be hard deleted.
+func (c *CompatibilityCheck) deleteVersion(ctx context.Context, subject string, version int) error {
+	if err := c.srClient.DeleteSchema(ctx, subject, version, franzsr.SoftDelete); err != nil {
+		return fmt.Errorf("failed to delete version %d of subject '%v': %w", version, subject, err)
+	}
+	if err := c.srClient.DeleteSchema(ctx, subject, version, franzsr.HardDelete); err != nil {
+		return fmt.Errorf("failed to delete version %d of subject '%v': %w", version, subject, err)
+	}
+
+	return nil
+}
+
+// breaksLevel returns true if the change violates the compatibility level.
+func breaksLevel(level franzsr.CompatibilityLevel, change incompatibleChange) bool {
+	switch level {
+	case franzsr.CompatBackward, franzsr.CompatBackwardTransitive:
+		return change.breaksBackward
+	case franzsr.CompatForward, franzsr.CompatForwardTransitive:
+		return change.breaksForward
+	case franzsr.CompatFull, franzsr.CompatFullTransitive:
+		return change.breaksBackward || change.breaksForward
+	}
+
+	return false
+}
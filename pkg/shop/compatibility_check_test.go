@@ -0,0 +1,75 @@
+package shop
+
+import (
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	franzsr "github.com/twmb/franz-go/pkg/sr"
+
+	embedjson "github.com/cloudhut/owl-shop/pkg/shop/schemas/json"
+)
+
+func TestIncompatibleChangesApplyToAllVersions(t *testing.T) {
+	versions := append([]customerSchemaVersion{baseCustomerSchemaVersion}, customerSchemaVersions...)
+	for _, change := range incompatibleChanges {
+		for _, version := range versions {
+			var latest string
+			switch change.schemaType {
+			case franzsr.TypeProtobuf:
+				latest = version.protobufSchema
+			case franzsr.TypeAvro:
+				latest = version.avroSchema
+			case franzsr.TypeJSON:
+				// JSON schemas are not evolved
+				latest = embedjson.CustomerJSON
+			}
+
+			changed, err := change.schema(latest)
+			if err != nil {
+				t.Errorf("%v: failed to change schema %v: %v", change.description, version.name, err)
+				continue
+			}
+			if changed == latest {
+				t.Errorf("%v: schema %v has not been changed", change.description, version.name)
+			}
+			if change.schemaType == franzsr.TypeAvro {
+				if _, err := avro.ParseWithCache(changed, "", &avro.SchemaCache{}); err != nil {
+					t.Errorf("%v: changed schema %v is invalid: %v", change.description, version.name, err)
+				}
+			}
+		}
+	}
+}
+
+func TestBreaksLevel(t *testing.T) {
+	backward := incompatibleChange{breaksBackward: true}
+	forward := incompatibleChange{breaksForward: true}
+	tests := []struct {
+		level         franzsr.CompatibilityLevel
+		wantBackward  bool
+		wantForward   bool
+		wantBothSides bool
+	}{
+		{level: franzsr.CompatNone},
+		{level: franzsr.CompatBackward, wantBackward: true, wantBothSides: true},
+		{level: franzsr.CompatBackwardTransitive, wantBackward: true, wantBothSides: true},
+		{level: franzsr.CompatForward, wantForward: true, wantBothSides: true},
+		{level: franzsr.CompatForwardTransitive, wantForward: true, wantBothSides: true},
+		{level: franzsr.CompatFull, wantBackward: true, wantForward: true, wantBothSides: true},
+		{level: franzsr.CompatFullTransitive, wantBackward: true, wantForward: true, wantBothSides: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			if got := breaksLevel(tt.level, backward); got != tt.wantBackward {
+				t.Errorf("backward incompatible change: breaksLevel() = %v, want %v", got, tt.wantBackward)
+			}
+			if got := breaksLevel(tt.level, forward); got != tt.wantForward {
+				t.Errorf("forward incompatible change: breaksLevel() = %v, want %v", got, tt.wantForward)
+			}
+			both := incompatibleChange{breaksBackward: true, breaksForward: true}
+			if got := breaksLevel(tt.level, both); got != tt.wantBothSides {
+				t.Errorf("fully incompatible change: breaksLevel() = %v, want %v", got, tt.wantBothSides)
+			}
+		})
+	}
+}
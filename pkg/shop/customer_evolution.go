@@ -0,0 +1,392 @@
+package shop
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sr"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/fake"
+	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
+	embedavro "github.com/cloudhut/owl-shop/pkg/shop/schemas/avro"
+	embedproto "github.com/cloudhut/owl-shop/proto"
+)
+
+// customerSchemaVersion is a version of the customer schemas. The protobuf
+// message is derived from the generated customer message, because there is no
+// generated code for the evolved versions.
+type customerSchemaVersion struct {
+	name           string
+	change         string
+	protobufSchema string
+	avroSchema     string
+	// evolveProtobuf applies the change to the customer message descriptor of
+	// the previous version.
+	evolveProtobuf func(msg *descriptorpb.DescriptorProto)
+	// governmentType is true if the version knows the customer type GOVERNMENT.
+	governmentType bool
+}
+
+// baseCustomerSchemaVersion is the version the customer topics are initialized with.
+var baseCustomerSchemaVersion = customerSchemaVersion{
+	name:           "v2",
+	protobufSchema: embedproto.CustomerV2,
+	avroSchema:     embedavro.CustomerV2Avro,
+}
+
+// customerSchemaVersions are the compatible customer schema versions that are
+// registered one after another by the schema evolution.
+var customerSchemaVersions = []customerSchemaVersion{
+	{
+		name:           "v3",
+		change:         "added optional field loyaltyTier",
+		protobufSchema: embedproto.CustomerV3,
+		avroSchema:     embedavro.CustomerV3Avro,
+		evolveProtobuf: func(msg *descriptorpb.DescriptorProto) {
+			msg.Field = append(msg.Field, &descriptorpb.FieldDescriptorProto{
+				Name:     proto.String("loyalty_tier"),
+				Number:   proto.Int32(10),
+				Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+				Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+				JsonName: proto.String("loyaltyTier"),
+			})
+		},
+	},
+	{
+		name:           "v4",
+		change:         "renamed field email to emailAddress",
+		protobufSchema: embedproto.CustomerV4,
+		avroSchema:     embedavro.CustomerV4Avro,
+		evolveProtobuf: func(msg *descriptorpb.DescriptorProto) {
+			for _, field := range msg.Field {
+				if field.GetName() == "email" {
+					field.Name = proto.String("email_address")
+					field.JsonName = proto.String("emailAddress")
+				}
+			}
+		},
+	},
+	{
+		name:           "v5",
+		change:         "added customer type GOVERNMENT",
+		protobufSchema: embedproto.CustomerV5,
+		avroSchema:     embedavro.CustomerV5Avro,
+		evolveProtobuf: func(msg *descriptorpb.DescriptorProto) {
+			customerType := msg.EnumType[0]
+			customerType.Value = append(customerType.Value, &descriptorpb.EnumValueDescriptorProto{
+				Name:   proto.String("CUSTOMER_TYPE_GOVERNMENT"),
+				Number: proto.Int32(3),
+			})
+		},
+		governmentType: true,
+	},
+}
+
+// evolvedCustomer has the fields of all customer schema versions. Fields that
+// are not part of a version's Avro schema are ignored when encoding.
+type evolvedCustomer struct {
+	Version      int     `json:"version"`
+	ID           string  `json:"id"`
+	FirstName    string  `json:"firstName"`
+	LastName     string  `json:"lastName"`
+	Gender       string  `json:"gender"`
+	CompanyName  *string `json:"companyName"`
+	Email        string  `json:"email"`
+	EmailAddress string  `json:"emailAddress"`
+	CustomerType string  `json:"customerType"`
+	Revision     int     `json:"revision"`
+	LoyaltyTier  *string `json:"loyaltyTier"`
+}
+
+// loyaltyTier derives the loyalty tier from the customer id, so that it is
+// stable across the revisions of a customer.
+func loyaltyTier(customerID string) *string {
+	tiers := []string{"", "BRONZE", "SILVER", "GOLD"}
+	tier := tiers[crc32.ChecksumIEEE([]byte(customerID))%uint32(len(tiers))]
+	if tier == "" {
+		return nil
+	}
+	return &tier
+}
+
+// isGovernment returns true for a fifth of the business customers, which are
+// produced as government customers by versions that know this customer type.
+func isGovernment(customer fake.Customer) bool {
+	return customer.CustomerType == fake.CustomerTypeBusiness && crc32.ChecksumIEEE([]byte(customer.ID))%5 == 0
+}
+
+// customerEncoder encodes customers with a registered customer schema version.
+type customerEncoder struct {
+	version        customerSchemaVersion
+	protobufID     int
+	protobufType   protoreflect.MessageType
+	avroID         int
+	avroSchema     avro.Schema
+	protobufSource *descriptorpb.FileDescriptorProto
+}
+
+func (e *customerEncoder) encodeProtobuf(customer fake.Customer) ([]byte, error) {
+	serialized, err := protoAPI.Marshal(customer.Protobuf())
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize customer: %w", err)
+	}
+	msg := e.protobufType.New()
+	if err := proto.Unmarshal(serialized, msg.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to convert customer to schema version %v: %w", e.version.name, err)
+	}
+	fields := msg.Descriptor().Fields()
+	if field := fields.ByName("loyalty_tier"); field != nil {
+		if tier := loyaltyTier(customer.ID); tier != nil {
+			msg.Set(field, protoreflect.ValueOfString(*tier))
+		}
+	}
+	if e.version.governmentType && isGovernment(customer) {
+		msg.Set(fields.ByName("customer_type"), protoreflect.ValueOfEnum(3))
+	}
+
+	payload, err := protoAPI.Marshal(msg.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize customer: %w", err)
+	}
+	var header sr.ConfluentHeader
+	encoded, err := header.AppendEncode(nil, e.protobufID, []int{0})
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, payload...), nil
+}
+
+func (e *customerEncoder) encodeAvro(customer fake.Customer) ([]byte, error) {
+	value := evolvedCustomer{
+		Version:      customer.Version,
+		ID:           customer.ID,
+		FirstName:    customer.FirstName,
+		LastName:     customer.LastName,
+		Gender:       customer.Gender,
+		CompanyName:  customer.CompanyName,
+		Email:        customer.Email,
+		EmailAddress: customer.Email,
+		CustomerType: string(customer.CustomerType),
+		Revision:     customer.Revision,
+		LoyaltyTier:  loyaltyTier(customer.ID),
+	}
+	if e.version.governmentType && isGovernment(customer) {
+		value.CustomerType = "GOVERNMENT"
+	}
+
+	payload, err := avroAPI.Marshal(e.avroSchema, value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize customer: %w", err)
+	}
+	var header sr.ConfluentHeader
+	encoded, err := header.AppendEncode(nil, e.avroID, nil)
+	if err != nil {
+		return nil, err
+	}
+	return append(encoded, payload...), nil
+}
+
+// customerEvolution registers the customer schema versions of the configured
+// timeline and re-encodes the records of the protobuf and Avro customer topics
+// with the current version, or the previous version for lagging producers.
+type customerEvolution struct {
+	cfg      config.ShopSchemaEvolution
+	logger   *zap.Logger
+	srClient schemaRegistryClient
+
+	protobufTopic   string
+	protobufSubject string
+	avroTopic       string
+	avroSubject     string
+
+	startedAt time.Time
+	events    atomic.Uint64
+	records   atomic.Uint64
+
+	mu sync.RWMutex
+	// encoders of all registered versions, starting with the base version
+	encoders []*customerEncoder
+	// failed is set once a step could not be registered. All later versions
+	// build upon the failed one, so that the timeline ends with it.
+	failed bool
+}
+
+func newCustomerEvolution(
+	cfg config.ShopSchemaEvolution,
+	logger *zap.Logger,
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
+	protobufTopic string,
+	avroTopic string,
+) *customerEvolution {
+	return &customerEvolution{
+		cfg:             cfg,
+		logger:          logger,
+		srClient:        srClient,
+		protobufTopic:   protobufTopic,
+		protobufSubject: subjects.subject(protobufTopic, protobufRecordName("Customer")),
+		avroTopic:       avroTopic,
+		avroSubject:     subjects.subject(avroTopic, avroRecordName("Customer")),
+	}
+}
+
+// Initialize looks up the schema ids of the base version, which must already be
+// registered, and starts the timeline.
+func (e *customerEvolution) Initialize(ctx context.Context) error {
+	base := protodesc.ToFileDescriptorProto(shoppb.File_shop_v1_customer_proto)
+	encoder, err := e.register(ctx, baseCustomerSchemaVersion, base)
+	if err != nil {
+		return fmt.Errorf("failed to register base customer schema version: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.encoders = []*customerEncoder{encoder}
+	e.startedAt = time.Now()
+
+	return nil
+}
+
+// register registers the schemas of the given version and creates its encoder.
+// The protobuf descriptor is the descriptor of the version's file.
+func (e *customerEvolution) register(
+	ctx context.Context,
+	version customerSchemaVersion,
+	protobufSource *descriptorpb.FileDescriptorProto,
+) (*customerEncoder, error) {
+	file, err := protodesc.NewFile(protobufSource, protoregistry.GlobalFiles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build protobuf descriptor: %w", err)
+	}
+	// Evolved versions redefine the Customer record, which must not replace
+	// the base version in the global cache.
+	avroSchema, err := avro.ParseWithCache(version.avroSchema, "", &avro.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse avro schema: %w", err)
+	}
+
+	protobufSchema, err := e.srClient.CreateSchema(ctx, e.protobufSubject, sr.Schema{
+		Schema: version.protobufSchema,
+		Type:   sr.TypeProtobuf,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register protobuf schema: %w", err)
+	}
+	avroSubjectSchema, err := e.srClient.CreateSchema(ctx, e.avroSubject, sr.Schema{
+		Schema: version.avroSchema,
+		Type:   sr.TypeAvro,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to register avro schema: %w", err)
+	}
+
+	return &customerEncoder{
+		version:        version,
+		protobufID:     protobufSchema.ID,
+		protobufType:   dynamicpb.NewMessageType(file.Messages().ByName("Customer")),
+		avroID:         avroSubjectSchema.ID,
+		avroSchema:     avroSchema,
+		protobufSource: protobufSource,
+	}, nil
+}
+
+// evolve registers the next schema version if its step is due. If the version
+// can't be registered (e.g. because the registry's compatibility level rejects
+// it), the failure is logged and the current version is kept.
+func (e *customerEvolution) evolve(ctx context.Context, events uint64) {
+	e.mu.RLock()
+	applied := len(e.encoders) - 1
+	failed := e.failed
+	e.mu.RUnlock()
+	if failed || applied >= len(e.cfg.Steps) {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Another producer may have applied the step in the meantime
+	applied = len(e.encoders) - 1
+	if e.failed || applied >= len(e.cfg.Steps) {
+		return
+	}
+	step := e.cfg.Steps[applied]
+	due := (step.After > 0 && time.Since(e.startedAt) >= step.After) ||
+		(step.AfterCustomerEvents > 0 && events >= step.AfterCustomerEvents)
+	if !due {
+		return
+	}
+
+	version := customerSchemaVersions[applied]
+	protobufSource := proto.Clone(e.encoders[applied].protobufSource).(*descriptorpb.FileDescriptorProto)
+	version.evolveProtobuf(protobufSource.MessageType[0])
+	encoder, err := e.register(ctx, version, protobufSource)
+	if err != nil {
+		e.failed = true
+		e.logger.Error("failed to evolve customer schemas, keeping the current version for the rest of the run",
+			zap.String("version", version.name),
+			zap.String("change", version.change),
+			zap.String("current_version", e.encoders[applied].version.name),
+			zap.Error(err))
+		return
+	}
+	e.encoders = append(e.encoders, encoder)
+	e.logger.Info("evolved customer schemas",
+		zap.String("version", version.name),
+		zap.String("change", version.change),
+		zap.Int("protobuf_schema_id", encoder.protobufID),
+		zap.Int("avro_schema_id", encoder.avroID))
+}
+
+// Apply counts the customer event, evolves the schemas if the next step is due
+// and re-encodes the customer records of the protobuf and Avro topics.
+func (e *customerEvolution) Apply(ctx context.Context, customer fake.Customer, records []*kgo.Record) error {
+	e.evolve(ctx, e.events.Add(1))
+
+	e.mu.RLock()
+	encoder := e.encoders[len(e.encoders)-1]
+	if len(e.encoders) > 1 && e.isLagging() {
+		encoder = e.encoders[len(e.encoders)-2]
+	}
+	e.mu.RUnlock()
+
+	for _, rec := range records {
+		var err error
+		switch rec.Topic {
+		case e.protobufTopic:
+			rec.Value, err = encoder.encodeProtobuf(customer)
+		case e.avroTopic:
+			rec.Value, err = encoder.encodeAvro(customer)
+		default:
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		rec.Headers = append(rec.Headers, kgo.RecordHeader{Key: "schema_version", Value: []byte(encoder.version.name)})
+	}
+
+	return nil
+}
+
+// isLagging returns true if the next record is produced by a lagging producer.
+// Lagging records are spread evenly according to the configured ratio.
+func (e *customerEvolution) isLagging() bool {
+	n := e.records.Add(1)
+	ratio := e.cfg.LaggingProducerRatio
+	return uint64(float64(n)*ratio) != uint64(float64(n-1)*ratio)
+}
@@ -0,0 +1,77 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sr"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/fake"
+	owlsr "github.com/cloudhut/owl-shop/pkg/sr"
+)
+
+// rejectingRegistry accepts the first schemas and rejects all later ones, like
+// a registry whose compatibility level rejects a schema change.
+type rejectingRegistry struct {
+	*owlsr.InMemoryRegistry
+	accepted int
+	calls    int
+}
+
+func (r *rejectingRegistry) CreateSchema(ctx context.Context, subject string, s sr.Schema) (sr.SubjectSchema, error) {
+	r.calls++
+	if r.calls > r.accepted {
+		return sr.SubjectSchema{}, errors.New("schema being registered is incompatible with an earlier schema")
+	}
+	return r.InMemoryRegistry.CreateSchema(ctx, subject, s)
+}
+
+func TestCustomerEvolutionKeepsVersionOnFailedStep(t *testing.T) {
+	// The base version and the first step are accepted (2 schemas each)
+	registry := &rejectingRegistry{InMemoryRegistry: owlsr.NewInMemoryRegistry(), accepted: 4}
+	cfg := config.ShopSchemaEvolution{Steps: []config.ShopSchemaEvolutionStep{
+		{AfterCustomerEvents: 1},
+		{AfterCustomerEvents: 2},
+		{AfterCustomerEvents: 3},
+	}}
+	evolution := newCustomerEvolution(cfg, zap.NewNop(), registry,
+		newSubjectNamer(config.SchemaRegistry{SubjectNameStrategy: config.SubjectNameStrategyTopicName}),
+		"customers-protobuf-sr", "customers-avro-sr")
+	if err := evolution.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	generator := fake.NewGenerator(1)
+	for i := 0; i < 5; i++ {
+		records := []*kgo.Record{{Topic: "customers-protobuf-sr"}, {Topic: "customers-avro-sr"}}
+		if err := evolution.Apply(context.Background(), generator.NewCustomer(), records); err != nil {
+			t.Fatalf("Apply() error = %v", err)
+		}
+		for _, rec := range records {
+			if len(rec.Value) == 0 {
+				t.Errorf("record %d of topic %v has not been encoded", i, rec.Topic)
+			}
+			if version := schemaVersionHeader(rec); version != "v3" {
+				t.Errorf("record %d of topic %v has been encoded with version %v, want v3", i, rec.Topic, version)
+			}
+		}
+	}
+
+	// The failed step must not be retried
+	if registry.calls != registry.accepted+1 {
+		t.Errorf("registry has been called %d times, want %d", registry.calls, registry.accepted+1)
+	}
+}
+
+func schemaVersionHeader(rec *kgo.Record) string {
+	for _, header := range rec.Headers {
+		if header.Key == "schema_version" {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
@@ -5,16 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"sync"
-	"time"
 
-	"github.com/brianvoe/gofakeit/v5"
-	"github.com/twmb/franz-go/pkg/kadm"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"go.uber.org/zap"
 
 	"github.com/cloudhut/owl-shop/pkg/config"
 	"github.com/cloudhut/owl-shop/pkg/fake"
-	"github.com/cloudhut/owl-shop/pkg/kafka"
+	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
+	embedavro "github.com/cloudhut/owl-shop/pkg/shop/schemas/avro"
+	embedjson "github.com/cloudhut/owl-shop/pkg/shop/schemas/json"
+	"github.com/cloudhut/owl-shop/pkg/sink"
+	embedproto "github.com/cloudhut/owl-shop/proto"
 )
 
 // CustomerService emulates a service that produces a new Kafka record onto
@@ -26,8 +27,13 @@ type CustomerService struct {
 	cfg    config.Shop
 	logger *zap.Logger
 
-	kafkaFactory *kafka.Factory
-	metaClient   *kgo.Client
+	generator *fake.Generator
+	sink      sink.Sink
+	keys      *recordKeys
+	// schemaTopics is nil if schema registry hasn't been configured
+	schemaTopics *schemaTopics
+	// evolution is nil if no schema evolution has been configured
+	evolution *customerEvolution
 
 	bufferSize        int
 	recentCustomersMu sync.RWMutex
@@ -36,55 +42,104 @@ type CustomerService struct {
 	topicName string
 }
 
-// NewCustomerService creates a new CustomerService.
+// NewCustomerService creates a new CustomerService. The schema registry client is
+// optional and may be nil, in which case customers are only produced as JSON. It
+// is required if keys are encoded with a schema registry format.
 func NewCustomerService(
 	cfg config.Shop,
 	logger *zap.Logger,
-	kafkaFactory *kafka.Factory,
+	sinkFactory sink.Factory,
+	generator *fake.Generator,
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
 ) (*CustomerService, error) {
 	clientID := cfg.GlobalPrefix + "customer-service"
-	metaClient, err := kafkaFactory.NewKafkaClient(clientID)
+	recordSink, err := sinkFactory.NewSink(clientID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create kafka client: %w", err)
+		return nil, fmt.Errorf("failed to create sink: %w", err)
 	}
 
 	// This slice is used to keep some customers in the buffer so that they can be modified or deleted
 	bufferSize := 500
 	recentCustomers := make([]fake.Customer, 0, bufferSize)
 
+	keys, err := newRecordKeys(cfg.Keys, srClient, subjects, "Customer", &shoppb.CustomerKey{}, embedavro.CustomerKeyAvro)
+	if err != nil {
+		return nil, err
+	}
+
+	var customerSchemaTopics *schemaTopics
+	var evolution *customerEvolution
+	if srClient != nil {
+		topicCfg := map[string]string{"cleanup.policy": "compact"}
+		avroTopic := newSinkTopic(cfg, config.ShopTopicCustomersAvroSr, sink.FormatAvroSR, topicCfg)
+		avroTopic.Schema = embedavro.CustomerV2Avro
+		jsonTopic := newSinkTopic(cfg, config.ShopTopicCustomersJsonSr, sink.FormatJSONSR, topicCfg)
+		jsonTopic.Schema = embedjson.CustomerJSON
+		customerSchemaTopics = newSchemaTopics(
+			srClient,
+			subjects,
+			keys,
+			"Customer",
+			newSinkTopic(cfg, config.ShopTopicCustomersProtobufSr, sink.FormatProtobufSR, topicCfg),
+			embedproto.CustomerV2,
+			&shoppb.Customer{},
+			avroTopic,
+			jsonTopic,
+		)
+		if len(cfg.SchemaEvolution.Steps) > 0 {
+			evolution = newCustomerEvolution(
+				cfg.SchemaEvolution,
+				logger,
+				srClient,
+				subjects,
+				cfg.Topic(config.ShopTopicCustomersProtobufSr).Name,
+				avroTopic.Name,
+			)
+		}
+	}
+
 	return &CustomerService{
 		cfg:    cfg,
 		logger: logger.With(zap.String("service", "customer_service")),
 
-		kafkaFactory: kafkaFactory,
-		metaClient:   metaClient,
+		generator:    generator,
+		sink:         recordSink,
+		keys:         keys,
+		schemaTopics: customerSchemaTopics,
+		evolution:    evolution,
 
 		bufferSize:        bufferSize,
 		recentCustomersMu: sync.RWMutex{},
 		recentCustomers:   recentCustomers,
 
-		topicName: cfg.GlobalPrefix + "customers",
+		topicName: cfg.Topic(config.ShopTopicCustomers).Name,
 	}, nil
 }
 
-// Initialize creates the customer topic with cleanup policy compact.
+// Initialize creates the customer topics with cleanup policy compact and registers
+// the customer schemas if schema registry has been configured.
 func (svc *CustomerService) Initialize(ctx context.Context) error {
 	svc.logger.Info("initializing customer service")
 
-	err := kafka.ReconcileTopic(
-		ctx,
-		svc.metaClient,
-		svc.topicName,
-		svc.cfg.TopicPartitionCount,
-		svc.cfg.TopicReplicationFactor,
-		map[string]*string{
-			"cleanup.policy": kadm.StringPtr("compact"),
-		},
-	)
+	err := svc.keys.CreateTopic(ctx, svc.sink, newSinkTopic(svc.cfg, config.ShopTopicCustomers, sink.FormatJSON, map[string]string{
+		"cleanup.policy": "compact",
+	}))
 	if err != nil {
 		return fmt.Errorf("failed to reconcile topic: %w", err)
 	}
 
+	if svc.schemaTopics != nil {
+		if err := svc.schemaTopics.Initialize(ctx, svc.sink, fake.Customer{}); err != nil {
+			return fmt.Errorf("failed to initialize schema registry topics: %w", err)
+		}
+	}
+	if svc.evolution != nil {
+		if err := svc.evolution.Initialize(ctx); err != nil {
+			return fmt.Errorf("failed to initialize schema evolution: %w", err)
+		}
+	}
+
 	svc.logger.Info("successfully initialized customer service")
 
 	return nil
@@ -92,62 +147,74 @@ func (svc *CustomerService) Initialize(ctx context.Context) error {
 
 // CreateCustomer creates a fake customer struct and then produces the JSON serialized
 // customer to the customer's topic.
-func (svc *CustomerService) CreateCustomer() {
-	customer := fake.NewCustomer()
+func (svc *CustomerService) CreateCustomer() error {
+	customer := svc.generator.NewCustomer()
 	svc.recentCustomersMu.Lock()
 	if len(svc.recentCustomers) < svc.bufferSize {
 		svc.recentCustomers = append(svc.recentCustomers, customer)
 	}
 	svc.recentCustomersMu.Unlock()
 
-	err := svc.produceCustomer(customer)
+	produced, err := svc.produceCustomer(customer)
 	if err != nil {
 		svc.logger.Warn("failed to produce customer", zap.Error(err))
-		return
+		return err
 	}
-	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeCustomerCreated}).Inc()
-	return
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeCustomerCreated}).Add(float64(produced))
+	return nil
 }
 
 // ModifyCustomer takes an existing customer from the cache, modifies the last name
 // and sends the updated customer version to the customer's topic.
-func (svc *CustomerService) ModifyCustomer() {
+func (svc *CustomerService) ModifyCustomer() error {
 	customer, err := svc.popCustomerFromBuffer()
 	if err != nil {
 		svc.logger.Debug("failed to pop customer from buffer", zap.Error(err))
-		return
+		return err
 	}
 
-	customer.LastName = gofakeit.LastName()
-	customer.Revision++
+	customer = svc.generator.ModifyCustomer(customer)
 	svc.logger.Debug("modified customer")
 
-	err = svc.produceCustomer(customer)
+	produced, err := svc.produceCustomer(customer)
 	if err != nil {
 		svc.logger.Warn("failed to produce customer", zap.Error(err))
-		return
+		return err
 	}
-	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeCustomerModified}).Inc()
-	return
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeCustomerModified}).Add(float64(produced))
+	return nil
 }
 
 // DeleteCustomer sends a tombstone for an existing customer that was stored
 // in the customer cache.
-func (svc *CustomerService) DeleteCustomer() {
+func (svc *CustomerService) DeleteCustomer() error {
 	customer, err := svc.popCustomerFromBuffer()
 	if err != nil {
 		svc.logger.Debug("failed to pop customer from buffer", zap.Error(err))
-		return
+		return err
 	}
 
 	svc.logger.Debug("deleted customer")
 
-	svc.produceTombstone(customer.ID)
+	produced, err := svc.produceTombstone(customer.ID)
 	if err != nil {
-		svc.logger.Warn("failed to produce customer tombstone", zap.Error(err))
-		return
+		svc.logger.Warn("failed to produce tombstone", zap.Error(err))
+		return err
+	}
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeCustomerDeleted}).Add(float64(produced))
+
+	return nil
+}
+
+// Close flushes all buffered customer records and closes the sink.
+func (svc *CustomerService) Close(ctx context.Context) error {
+	defer svc.sink.Close()
+
+	if err := svc.sink.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush buffered records: %w", err)
 	}
-	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeCustomerDeleted}).Inc()
+
+	return nil
 }
 
 func (svc *CustomerService) popCustomerFromBuffer() (fake.Customer, error) {
@@ -156,7 +223,7 @@ func (svc *CustomerService) popCustomerFromBuffer() (fake.Customer, error) {
 
 	if len(svc.recentCustomers) == 0 {
 		// No customers in buffer yet
-		return fake.Customer{}, fmt.Errorf("buffer is empty")
+		return fake.Customer{}, errBufferEmpty
 	}
 	customer := svc.recentCustomers[0]
 	svc.recentCustomers = svc.recentCustomers[1:]
@@ -164,51 +231,89 @@ func (svc *CustomerService) popCustomerFromBuffer() (fake.Customer, error) {
 	return customer, nil
 }
 
-func (svc *CustomerService) produceTombstone(customerID string) {
-	rec := kgo.Record{
-		Key:       []byte(customerID),
+// produceTombstone produces tombstones for the customer to all customer topics
+// and returns the number of produced records.
+func (svc *CustomerService) produceTombstone(customerID string) (int, error) {
+	key, err := svc.keys.Encode(svc.topicName, customerID)
+	if err != nil {
+		return 0, err
+	}
+	deletedAt := svc.generator.Now()
+	records := []*kgo.Record{{
+		Key:       key,
 		Value:     nil,
-		Timestamp: time.Now(),
+		Timestamp: deletedAt,
 		Topic:     svc.topicName,
-	}
-
-	svc.metaClient.Produce(context.Background(), &rec, func(rec *kgo.Record, err error) {
+	}}
+	if svc.schemaTopics != nil {
+		srRecords, err := svc.schemaTopics.Tombstones(customerID, deletedAt)
 		if err != nil {
-			svc.logger.Error("failed to produce tombstone record",
-				zap.String("topic_name", rec.Topic),
-				zap.Error(err),
-			)
-			return
+			return 0, err
 		}
-	})
+		records = append(records, srRecords...)
+	}
+
+	for _, rec := range records {
+		svc.sink.Produce(context.Background(), rec, func(rec *kgo.Record, err error) {
+			if err != nil {
+				svc.logger.Error("failed to produce tombstone record",
+					zap.String("topic_name", rec.Topic),
+					zap.Error(err),
+				)
+				return
+			}
+		})
+	}
+
+	return len(records), nil
 }
 
-func (svc *CustomerService) produceCustomer(customer fake.Customer) error {
+// produceCustomer produces the customer to all customer topics and returns the
+// number of produced records.
+func (svc *CustomerService) produceCustomer(customer fake.Customer) (int, error) {
 	serialized, err := json.Marshal(customer)
 	if err != nil {
-		return fmt.Errorf("failed to serialize customer struct: %w", err)
+		return 0, fmt.Errorf("failed to serialize customer struct: %w", err)
 	}
 
-	rec := kgo.Record{
-		Key:       []byte(customer.ID),
-		Value:     serialized,
-		Headers:   []kgo.RecordHeader{{Key: "revision", Value: []byte("0")}},
-		Timestamp: time.Now(),
-		Topic:     svc.topicName,
+	key, err := svc.keys.Encode(svc.topicName, customer.ID)
+	if err != nil {
+		return 0, err
 	}
 
-	svc.metaClient.Produce(context.Background(), &rec, func(rec *kgo.Record, err error) {
+	producedAt := svc.generator.Now()
+	headers := []kgo.RecordHeader{{Key: "revision", Value: []byte("0")}}
+	records := []*kgo.Record{{
+		Key:       key,
+		Value:     serialized,
+		Headers:   headers,
+		Timestamp: producedAt,
+		Topic:     svc.topicName,
+	}}
+	if svc.schemaTopics != nil {
+		srRecords, err := svc.schemaTopics.Records(customer.ID, producedAt, headers, customer.Protobuf(), customer)
 		if err != nil {
-			svc.logger.Error("failed to produce record",
-				zap.String("topic_name", rec.Topic),
-				zap.Error(err),
-			)
-			return
+			return 0, err
 		}
-	})
-	if err != nil {
-		return fmt.Errorf("failed to produce: %w", err)
+		if svc.evolution != nil {
+			if err := svc.evolution.Apply(context.Background(), customer, srRecords); err != nil {
+				return 0, err
+			}
+		}
+		records = append(records, srRecords...)
 	}
 
-	return nil
+	for _, rec := range records {
+		svc.sink.Produce(context.Background(), rec, func(rec *kgo.Record, err error) {
+			if err != nil {
+				svc.logger.Error("failed to produce record",
+					zap.String("topic_name", rec.Topic),
+					zap.Error(err),
+				)
+				return
+			}
+		})
+	}
+
+	return len(records), nil
 }
@@ -0,0 +1,283 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/fake"
+	"github.com/cloudhut/owl-shop/pkg/kafka"
+	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
+	embedavro "github.com/cloudhut/owl-shop/pkg/shop/schemas/avro"
+	"github.com/cloudhut/owl-shop/pkg/sink"
+)
+
+// DeliveryService consumes the orders topic and ships all orders that have been
+// shipped by the shop. Each status scan of a shipment is produced to the
+// shipments topic. Delivered shipments are consumed by the OrderService, which
+// then marks the order as delivered.
+type DeliveryService struct {
+	cfg       config.Shop
+	logger    *zap.Logger
+	generator *fake.Generator
+
+	sink sink.Sink
+	keys *recordKeys
+	// consumerClient is nil if records are not written to Kafka. In this case
+	// orders must be passed to HandleOrderRecord by the caller.
+	consumerClient *kgo.Client
+
+	bufferSize int
+	// shipmentsMu guards the shipments that are on their way. They are
+	// scanned in the order they have been created or last scanned.
+	shipmentsMu sync.Mutex
+	shipments   []fake.Shipment
+
+	topicName string
+}
+
+// NewDeliveryService creates the service that publishes shipments to the shipments
+// topic. The Kafka factory is only used for consuming orders and may be nil if
+// records are not written to Kafka. The schema registry client may be nil,
+// unless keys are encoded with a schema registry format.
+func NewDeliveryService(
+	cfg config.Shop,
+	logger *zap.Logger,
+	kafkaFactory *kafka.Factory,
+	sinkFactory sink.Factory,
+	generator *fake.Generator,
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
+) (*DeliveryService, error) {
+	clientID := cfg.GlobalPrefix + "delivery-service"
+
+	keys, err := newRecordKeys(cfg.Keys, srClient, subjects, "Shipment", &shoppb.ShipmentKey{}, embedavro.ShipmentKeyAvro)
+	if err != nil {
+		return nil, err
+	}
+
+	var consumerClient *kgo.Client
+	if kafkaFactory != nil {
+		opts := []kgo.Opt{
+			kgo.ConsumeTopics(cfg.Topic(config.ShopTopicOrders).Name),
+			kgo.ConsumerGroup(clientID),
+			kgo.AutoCommitInterval(500 * time.Millisecond),
+		}
+		if cfg.TransactionalOrders {
+			// Orders of aborted transactions must not be processed
+			opts = append(opts, kgo.FetchIsolationLevel(kgo.ReadCommitted()))
+		}
+		consumerClient, err = kafkaFactory.NewKafkaClient(clientID, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create consumer client: %w", err)
+		}
+	}
+
+	recordSink, err := sinkFactory.NewSink(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sink: %w", err)
+	}
+
+	bufferSize := 500
+
+	return &DeliveryService{
+		cfg:       cfg,
+		logger:    logger.With(zap.String("service", "delivery_service")),
+		generator: generator,
+
+		sink:           recordSink,
+		keys:           keys,
+		consumerClient: consumerClient,
+
+		bufferSize: bufferSize,
+		shipments:  make([]fake.Shipment, 0, bufferSize),
+
+		topicName: cfg.Topic(config.ShopTopicShipments).Name,
+	}, nil
+}
+
+// Initialize delivery service by reconciling the shipments topic.
+func (svc *DeliveryService) Initialize(ctx context.Context) error {
+	svc.logger.Info("initializing delivery service")
+
+	err := svc.keys.CreateTopic(ctx, svc.sink, newSinkTopic(svc.cfg, config.ShopTopicShipments, sink.FormatJSON, map[string]string{
+		"cleanup.policy": "delete",
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to reconcile topic: %w", err)
+	}
+
+	return nil
+}
+
+// Start consuming orders. It returns once the given context is cancelled or
+// immediately if there is no Kafka consumer.
+func (svc *DeliveryService) Start(ctx context.Context) {
+	if svc.consumerClient == nil {
+		return
+	}
+
+	for {
+		fetches := svc.consumerClient.PollFetches(ctx)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if fetches.IsClientClosed() {
+			svc.logger.Warn("client closed")
+			return
+		}
+
+		fetches.EachError(func(topic string, partition int32, err error) {
+			svc.logger.Error("failed to poll fetches",
+				zap.String("topic", topic),
+				zap.Int32("partition", partition),
+				zap.Error(err))
+		})
+
+		fetches.EachRecord(svc.HandleOrderRecord)
+	}
+}
+
+// HandleOrderRecord creates a shipment for the order of a record from the orders
+// topic, if the order has just been shipped.
+func (svc *DeliveryService) HandleOrderRecord(rec *kgo.Record) {
+	kafkaMessagesConsumedTotal.With(map[string]string{"event_type": EventTypeOrderConsumed}).Inc()
+
+	if rec.Value == nil {
+		return
+	}
+	order := fake.Order{}
+	if err := json.Unmarshal(rec.Value, &order); err != nil {
+		// Skip message
+		svc.logger.Warn("failed to deserialize order", zap.Error(err))
+		return
+	}
+	if order.Status != fake.OrderStatusShipped {
+		return
+	}
+
+	shipment := svc.generator.NewShipment(order)
+	if err := svc.produceShipment(shipment); err != nil {
+		svc.logger.Warn("failed to produce shipment", zap.Error(err))
+		return
+	}
+	svc.pushShipment(shipment)
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeShipmentCreated}).Inc()
+}
+
+// ScanShipment takes the next shipment that is on its way, scans it with the next
+// status and produces the scan to the shipments topic. Shipments that haven't
+// been delivered yet are put back, so that they are scanned again later on.
+func (svc *DeliveryService) ScanShipment() error {
+	previous, err := svc.popShipment()
+	if err != nil {
+		svc.logger.Debug("failed to pop shipment", zap.Error(err))
+		return err
+	}
+	shipment := svc.generator.ScanShipment(previous)
+
+	if err := svc.produceShipment(shipment); err != nil {
+		svc.logger.Warn("failed to produce shipment", zap.Error(err))
+		// Put the unchanged shipment back, so that it can be scanned again later on
+		svc.pushShipment(previous)
+		return err
+	}
+	if !shipment.IsDelivered() {
+		svc.pushShipment(shipment)
+	}
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeShipmentScanned}).Inc()
+
+	return nil
+}
+
+func (svc *DeliveryService) produceShipment(shipment fake.Shipment) error {
+	serialized, err := json.Marshal(shipment)
+	if err != nil {
+		return fmt.Errorf("failed to serialize shipment struct: %w", err)
+	}
+
+	key, err := svc.keys.Encode(svc.topicName, shipment.ID)
+	if err != nil {
+		return err
+	}
+
+	rec := &kgo.Record{
+		Key:       key,
+		Value:     serialized,
+		Headers:   []kgo.RecordHeader{{Key: "revision", Value: []byte(strconv.Itoa(shipment.Revision))}},
+		Timestamp: shipment.ScannedAt,
+		Topic:     svc.topicName,
+	}
+	svc.sink.Produce(context.Background(), rec, func(rec *kgo.Record, err error) {
+		if err != nil {
+			svc.logger.Error("failed to produce record",
+				zap.String("topic_name", rec.Topic),
+				zap.Error(err),
+			)
+		}
+	})
+
+	return nil
+}
+
+// Close commits the consumed offsets, flushes all buffered shipment records and
+// closes the Kafka consumer and the sink. Start must have returned before calling Close.
+func (svc *DeliveryService) Close(ctx context.Context) error {
+	defer svc.sink.Close()
+	if svc.consumerClient != nil {
+		defer svc.consumerClient.Close()
+	}
+
+	if err := svc.sink.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush buffered records: %w", err)
+	}
+
+	if svc.consumerClient != nil {
+		if err := svc.consumerClient.CommitUncommittedOffsets(ctx); err != nil {
+			return fmt.Errorf("failed to commit offsets: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// pushShipment adds the shipment to the shipments on their way. If the buffer is
+// full, the shipment that has been scanned least recently is evicted, so that it
+// is never delivered.
+func (svc *DeliveryService) pushShipment(shipment fake.Shipment) {
+	svc.shipmentsMu.Lock()
+	defer svc.shipmentsMu.Unlock()
+
+	if len(svc.shipments) >= svc.bufferSize {
+		evicted := svc.shipments[0]
+		svc.shipments = svc.shipments[1:]
+		svc.logger.Debug("evicted undelivered shipment, too many shipments are on their way",
+			zap.String("shipment_id", evicted.ID),
+			zap.String("order_id", evicted.OrderID),
+			zap.String("status", string(evicted.Status)))
+	}
+	svc.shipments = append(svc.shipments, shipment)
+}
+
+func (svc *DeliveryService) popShipment() (fake.Shipment, error) {
+	svc.shipmentsMu.Lock()
+	defer svc.shipmentsMu.Unlock()
+
+	if len(svc.shipments) == 0 {
+		// No shipments on their way yet
+		return fake.Shipment{}, errBufferEmpty
+	}
+	shipment := svc.shipments[0]
+	svc.shipments = svc.shipments[1:]
+
+	return shipment, nil
+}
@@ -0,0 +1,53 @@
+package shop
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/fake"
+)
+
+func TestDeliveryServiceEncodesShipmentKeys(t *testing.T) {
+	tests := []struct {
+		format string
+		want   func(shipment fake.Shipment) string
+	}{
+		{
+			format: config.KeyFormatString,
+			want:   func(shipment fake.Shipment) string { return shipment.ID },
+		},
+		{
+			format: config.KeyFormatJSON,
+			want: func(shipment fake.Shipment) string {
+				return `{"id":"` + shipment.ID + `","tenant":"owlshop"}`
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var cfg config.Shop
+			cfg.SetDefaults()
+			cfg.Keys.Format = tt.format
+			generator := fake.NewGenerator(1)
+			svc, stdout := newTestService(t, cfg, generator, NewDeliveryService)
+			produced := collectRecords(stdout, svc.topicName)
+
+			order := generator.NewOrder(generator.NewCustomer())
+			order.Status = fake.OrderStatusShipped
+			svc.HandleOrderRecord(jsonRecord(t, order))
+
+			if len(*produced) != 1 {
+				t.Fatalf("got %d shipment records, want 1", len(*produced))
+			}
+			rec := (*produced)[0]
+			var shipment fake.Shipment
+			if err := json.Unmarshal(rec.Value, &shipment); err != nil {
+				t.Fatalf("failed to deserialize shipment: %v", err)
+			}
+			if got := string(rec.Key); got != tt.want(shipment) {
+				t.Errorf("key = %v, want %v", got, tt.want(shipment))
+			}
+		})
+	}
+}
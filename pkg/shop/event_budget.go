@@ -0,0 +1,115 @@
+package shop
+
+import (
+	"sync"
+)
+
+// eventBudget counts the actions whose records were handed to the sink and
+// enforces the configured upper bounds for the total number of events and the
+// number of events per action. Executions must be reserved before they are
+// started, so that concurrently executed actions can not exceed the bounds.
+type eventBudget struct {
+	maxTotal     uint64
+	maxPerAction map[string]uint64
+
+	mu            sync.Mutex
+	reservedTotal uint64
+	executedTotal uint64
+	reserved      map[string]uint64
+	executed      map[string]uint64
+
+	exhaustedOnce sync.Once
+	exhausted     chan struct{}
+}
+
+// newEventBudget creates a new eventBudget. A maxTotal of 0 and actions that are
+// not part of maxPerAction are not bounded.
+func newEventBudget(maxTotal uint64, maxPerAction map[string]uint64) *eventBudget {
+	return &eventBudget{
+		maxTotal:     maxTotal,
+		maxPerAction: maxPerAction,
+		reserved:     make(map[string]uint64),
+		executed:     make(map[string]uint64),
+		exhausted:    make(chan struct{}),
+	}
+}
+
+// Reserve reserves the execution of the given action. It returns false if the
+// action must not be executed, because a bound would be exceeded otherwise.
+func (b *eventBudget) Reserve(action string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.maxTotal > 0 && b.reservedTotal >= b.maxTotal {
+		return false
+	}
+	if maxEvents, exists := b.maxPerAction[action]; exists && b.reserved[action] >= maxEvents {
+		return false
+	}
+
+	b.reservedTotal++
+	b.reserved[action]++
+
+	return true
+}
+
+// Complete releases a reservation. If the action has been executed successfully
+// it is counted, otherwise the reservation is returned to the budget. It returns
+// true if the action has reached its bound with this execution.
+func (b *eventBudget) Complete(action string, success bool) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !success {
+		b.reservedTotal--
+		b.reserved[action]--
+		return false
+	}
+
+	b.executedTotal++
+	b.executed[action]++
+
+	if b.isExhausted() {
+		b.exhaustedOnce.Do(func() { close(b.exhausted) })
+	}
+
+	maxEvents, exists := b.maxPerAction[action]
+	return exists && b.executed[action] == maxEvents
+}
+
+// isExhausted returns true if the total bound has been reached or if all actions
+// with a bound have reached it. The caller must hold the lock.
+func (b *eventBudget) isExhausted() bool {
+	if b.maxTotal > 0 && b.executedTotal >= b.maxTotal {
+		return true
+	}
+	if len(b.maxPerAction) == 0 {
+		return false
+	}
+	for action, maxEvents := range b.maxPerAction {
+		if b.executed[action] < maxEvents {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Exhausted returns a channel that is closed once the budget is exhausted.
+func (b *eventBudget) Exhausted() <-chan struct{} {
+	return b.exhausted
+}
+
+// Executed returns the total number of executed actions and a copy of the
+// number of executions per action.
+func (b *eventBudget) Executed() (uint64, map[string]uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	executed := make(map[string]uint64, len(b.executed))
+	for action, count := range b.executed {
+		executed[action] = count
+	}
+
+	return b.executedTotal, executed
+}
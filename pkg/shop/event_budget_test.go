@@ -0,0 +1,95 @@
+package shop
+
+import "testing"
+
+// budgetStep reserves an action and completes the reservation, if it has been
+// granted.
+type budgetStep struct {
+	action        string
+	success       bool
+	wantReserved  bool
+	wantReached   bool
+	wantExhausted bool
+}
+
+func TestEventBudget(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxTotal     uint64
+		maxPerAction map[string]uint64
+		steps        []budgetStep
+		wantExecuted uint64
+	}{
+		{
+			name: "unbounded",
+			steps: []budgetStep{
+				{action: "createOrder", success: true, wantReserved: true},
+				{action: "createOrder", success: true, wantReserved: true},
+			},
+			wantExecuted: 2,
+		},
+		{
+			name:     "total bound",
+			maxTotal: 2,
+			steps: []budgetStep{
+				{action: "createOrder", success: true, wantReserved: true},
+				{action: "createCustomer", success: true, wantReserved: true, wantExhausted: true},
+				{action: "createOrder"},
+			},
+			wantExecuted: 2,
+		},
+		{
+			name:     "failed executions are not counted",
+			maxTotal: 1,
+			steps: []budgetStep{
+				{action: "createOrder", success: false, wantReserved: true},
+				{action: "createOrder", success: true, wantReserved: true, wantExhausted: true},
+			},
+			wantExecuted: 1,
+		},
+		{
+			name:         "action bounds",
+			maxPerAction: map[string]uint64{"createOrder": 1, "createCustomer": 2},
+			steps: []budgetStep{
+				{action: "createOrder", success: true, wantReserved: true, wantReached: true},
+				{action: "createOrder"},
+				{action: "updateOrder", success: true, wantReserved: true},
+				{action: "createCustomer", success: true, wantReserved: true},
+				{action: "createCustomer", success: true, wantReserved: true, wantReached: true, wantExhausted: true},
+			},
+			wantExecuted: 4,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := newEventBudget(tt.maxTotal, tt.maxPerAction)
+			for i, step := range tt.steps {
+				if reserved := budget.Reserve(step.action); reserved != step.wantReserved {
+					t.Fatalf("step %d: Reserve() = %v, want %v", i, reserved, step.wantReserved)
+				}
+				if !step.wantReserved {
+					continue
+				}
+				if reached := budget.Complete(step.action, step.success); reached != step.wantReached {
+					t.Errorf("step %d: Complete() = %v, want %v", i, reached, step.wantReached)
+				}
+				if exhausted := isClosed(budget.Exhausted()); exhausted != step.wantExhausted {
+					t.Errorf("step %d: exhausted = %v, want %v", i, exhausted, step.wantExhausted)
+				}
+			}
+
+			if executed, _ := budget.Executed(); executed != tt.wantExecuted {
+				t.Errorf("Executed() = %v, want %v", executed, tt.wantExecuted)
+			}
+		})
+	}
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
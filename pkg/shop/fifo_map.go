@@ -0,0 +1,74 @@
+package shop
+
+// fifoMap is a map with a bounded number of entries. If a new entry is added to
+// a full map, the entry that has been added first is evicted. It is not safe for
+// concurrent use.
+type fifoMap[V any] struct {
+	capacity int
+	entries  map[string]V
+	// keys are the keys in the order they have been added. They may contain
+	// keys of removed entries, which are dropped lazily.
+	keys []string
+}
+
+func newFIFOMap[V any](capacity int) *fifoMap[V] {
+	return &fifoMap[V]{
+		capacity: capacity,
+		entries:  make(map[string]V, capacity),
+		keys:     make([]string, 0, capacity),
+	}
+}
+
+// Get returns the entry with the given key.
+func (m *fifoMap[V]) Get(key string) (V, bool) {
+	value, exists := m.entries[key]
+	return value, exists
+}
+
+// Put adds or replaces the entry with the given key. Replaced entries keep their
+// position. It returns the evicted entry, if an entry had to be evicted.
+func (m *fifoMap[V]) Put(key string, value V) (evicted V, isEvicted bool) {
+	if _, exists := m.entries[key]; exists {
+		m.entries[key] = value
+		return evicted, false
+	}
+
+	for len(m.entries) >= m.capacity {
+		oldest := m.keys[0]
+		m.keys = m.keys[1:]
+		if entry, exists := m.entries[oldest]; exists {
+			delete(m.entries, oldest)
+			evicted, isEvicted = entry, true
+		}
+	}
+	if len(m.keys) >= 2*m.capacity {
+		m.compact()
+	}
+	m.entries[key] = value
+	m.keys = append(m.keys, key)
+
+	return evicted, isEvicted
+}
+
+// Remove deletes the entry with the given key and returns it.
+func (m *fifoMap[V]) Remove(key string) (V, bool) {
+	value, exists := m.entries[key]
+	delete(m.entries, key)
+	return value, exists
+}
+
+// Len returns the number of entries.
+func (m *fifoMap[V]) Len() int {
+	return len(m.entries)
+}
+
+// compact drops the keys of removed entries.
+func (m *fifoMap[V]) compact() {
+	keys := make([]string, 0, len(m.entries))
+	for _, key := range m.keys {
+		if _, exists := m.entries[key]; exists {
+			keys = append(keys, key)
+		}
+	}
+	m.keys = keys
+}
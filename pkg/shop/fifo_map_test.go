@@ -0,0 +1,60 @@
+package shop
+
+import "testing"
+
+func TestFIFOMapEvictsOldestEntry(t *testing.T) {
+	m := newFIFOMap[int](2)
+
+	if _, isEvicted := m.Put("a", 1); isEvicted {
+		t.Fatalf("Put() evicted an entry from a map that is not full")
+	}
+	m.Put("b", 2)
+	// Replacing an entry keeps its position
+	if _, isEvicted := m.Put("a", 3); isEvicted {
+		t.Fatalf("Put() evicted an entry when replacing an existing entry")
+	}
+
+	evicted, isEvicted := m.Put("c", 4)
+	if !isEvicted || evicted != 3 {
+		t.Fatalf("Put() evicted = %v, %v, want 3, true", evicted, isEvicted)
+	}
+	if _, exists := m.Get("a"); exists {
+		t.Errorf("evicted entry still exists")
+	}
+	if value, _ := m.Get("c"); value != 4 {
+		t.Errorf("Get() = %v, want 4", value)
+	}
+	if m.Len() != 2 {
+		t.Errorf("Len() = %v, want 2", m.Len())
+	}
+}
+
+func TestFIFOMapSkipsRemovedEntries(t *testing.T) {
+	m := newFIFOMap[int](2)
+	m.Put("a", 1)
+	m.Put("b", 2)
+	if value, exists := m.Remove("a"); !exists || value != 1 {
+		t.Fatalf("Remove() = %v, %v, want 1, true", value, exists)
+	}
+
+	if _, isEvicted := m.Put("c", 3); isEvicted {
+		t.Fatalf("Put() evicted an entry although a slot has been freed")
+	}
+	evicted, isEvicted := m.Put("d", 4)
+	if !isEvicted || evicted != 2 {
+		t.Fatalf("Put() evicted = %v, %v, want 2, true", evicted, isEvicted)
+	}
+}
+
+func TestFIFOMapCompactsKeys(t *testing.T) {
+	m := newFIFOMap[int](2)
+	for i := 0; i < 100; i++ {
+		key := string(rune('a' + i%26))
+		m.Put(key, i)
+		m.Remove(key)
+	}
+
+	if len(m.keys) > 2*m.capacity {
+		t.Errorf("keys of removed entries are not dropped, got %d keys", len(m.keys))
+	}
+}
@@ -4,15 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 
-	"github.com/twmb/franz-go/pkg/kadm"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"go.uber.org/zap"
 
 	"github.com/cloudhut/owl-shop/pkg/config"
 	"github.com/cloudhut/owl-shop/pkg/fake"
-	"github.com/cloudhut/owl-shop/pkg/kafka"
+	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
+	embedavro "github.com/cloudhut/owl-shop/pkg/shop/schemas/avro"
+	embedjson "github.com/cloudhut/owl-shop/pkg/shop/schemas/json"
+	"github.com/cloudhut/owl-shop/pkg/sink"
+	embedproto "github.com/cloudhut/owl-shop/proto"
 )
 
 // FrontendService simulates a service that produces a Kafka message every
@@ -22,90 +24,147 @@ type FrontendService struct {
 	cfg    config.Shop
 	logger *zap.Logger
 
-	kafkaFactory *kafka.Factory
-	metaClient   *kgo.Client
+	generator *fake.Generator
+	sink      sink.Sink
+	// schemaTopics is nil if schema registry hasn't been configured
+	schemaTopics *schemaTopics
 
 	topicName string
 }
 
-// NewFrontendService creates a new FrontendService.
+// NewFrontendService creates a new FrontendService. The schema registry client is
+// optional and may be nil, in which case frontend events are only produced as JSON.
 func NewFrontendService(
 	cfg config.Shop,
 	logger *zap.Logger,
-	kafkaFactory *kafka.Factory,
+	sinkFactory sink.Factory,
+	generator *fake.Generator,
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
 ) (*FrontendService, error) {
 	clientID := cfg.GlobalPrefix + "frontend-service"
-	metaClient, err := kafkaFactory.NewKafkaClient(clientID)
+	recordSink, err := sinkFactory.NewSink(clientID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Kafka client: %w", err)
+		return nil, fmt.Errorf("failed to create sink: %w", err)
+	}
+
+	var eventSchemaTopics *schemaTopics
+	if srClient != nil {
+		topicCfg := frontendEventsTopicConfig()
+		avroTopic := newSinkTopic(cfg, config.ShopTopicFrontendEventsAvroSr, sink.FormatAvroSR, topicCfg)
+		avroTopic.Schema = embedavro.FrontendEventAvro
+		jsonTopic := newSinkTopic(cfg, config.ShopTopicFrontendEventsJsonSr, sink.FormatJSONSR, topicCfg)
+		jsonTopic.Schema = embedjson.FrontendEventJSON
+		eventSchemaTopics = newSchemaTopics(
+			srClient,
+			subjects,
+			nil, // Frontend events have no keys
+			"FrontendEvent",
+			newSinkTopic(cfg, config.ShopTopicFrontendEventsProtobufSr, sink.FormatProtobufSR, topicCfg),
+			embedproto.FrontendEvent,
+			&shoppb.FrontendEvent{},
+			avroTopic,
+			jsonTopic,
+		)
 	}
 
 	return &FrontendService{
 		cfg:    cfg,
 		logger: logger.With(zap.String("service", "frontend_service")),
 
-		kafkaFactory: kafkaFactory,
-		metaClient:   metaClient,
+		generator:    generator,
+		sink:         recordSink,
+		schemaTopics: eventSchemaTopics,
 
-		topicName: cfg.GlobalPrefix + "frontend-events",
+		topicName: cfg.Topic(config.ShopTopicFrontendEvents).Name,
 	}, nil
 }
 
 func (svc *FrontendService) Initialize(ctx context.Context) error {
 	svc.logger.Info("initializing frontend service")
-	err := kafka.ReconcileTopic(
-		ctx,
-		svc.metaClient,
-		svc.topicName,
-		svc.cfg.TopicPartitionCount,
-		svc.cfg.TopicReplicationFactor,
-		map[string]*string{
-			"cleanup.policy":  kadm.StringPtr("delete"),
-			"retention.bytes": kadm.StringPtr("3221225472"), // 3GiB
-		},
-	)
+	err := svc.sink.CreateTopic(ctx, newSinkTopic(svc.cfg, config.ShopTopicFrontendEvents, sink.FormatJSON, frontendEventsTopicConfig()))
 	if err != nil {
 		return fmt.Errorf("failed to reconcile topic: %w", err)
 	}
 
+	if svc.schemaTopics != nil {
+		if err := svc.schemaTopics.Initialize(ctx, svc.sink, fake.FrontendEvent{}); err != nil {
+			return fmt.Errorf("failed to initialize schema registry topics: %w", err)
+		}
+	}
+
 	svc.logger.Info("successfully initialized frontend service")
 
 	return nil
 }
 
-func (svc *FrontendService) CreateFrontendEvent() {
-	event := fake.NewFrontendEvent()
-	err := svc.produceFrontendEvent(event)
+func (svc *FrontendService) CreateFrontendEvent() error {
+	event := svc.generator.NewFrontendEvent()
+	produced, err := svc.produceFrontendEvent(event)
 	if err != nil {
 		svc.logger.Warn("failed to produce address", zap.Error(err))
-		return
+		return err
+	}
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeFrontendEventCreated}).Add(float64(produced))
+
+	return nil
+}
+
+// Close flushes all buffered frontend events and closes the sink.
+func (svc *FrontendService) Close(ctx context.Context) error {
+	defer svc.sink.Close()
+
+	if err := svc.sink.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush buffered records: %w", err)
 	}
-	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeFrontendEventCreated}).Inc()
+
+	return nil
 }
 
-func (svc *FrontendService) produceFrontendEvent(event fake.FrontendEvent) error {
+// produceFrontendEvent produces the event to all frontend event topics and
+// returns the number of produced records.
+func (svc *FrontendService) produceFrontendEvent(event fake.FrontendEvent) (int, error) {
 	serialized, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to serialize event struct: %w", err)
+		return 0, fmt.Errorf("failed to serialize event struct: %w", err)
 	}
 
-	rec := kgo.Record{
+	producedAt := svc.generator.Now()
+	records := []*kgo.Record{{
 		Key:       nil,
 		Value:     serialized,
 		Headers:   nil,
-		Timestamp: time.Now(),
+		Timestamp: producedAt,
 		Topic:     svc.topicName,
-	}
-
-	svc.metaClient.Produce(context.Background(), &rec, func(rec *kgo.Record, err error) {
+	}}
+	if svc.schemaTopics != nil {
+		srRecords, err := svc.schemaTopics.Records("", producedAt, nil, event.Protobuf(), event)
 		if err != nil {
-			svc.logger.Error("failed to produce record",
-				zap.String("topic_name", rec.Topic),
-				zap.Error(err),
-			)
-			return
+			return 0, err
 		}
-	})
+		records = append(records, srRecords...)
+	}
 
-	return nil
+	for _, rec := range records {
+		svc.sink.Produce(context.Background(), rec, func(rec *kgo.Record, err error) {
+			if err != nil {
+				svc.logger.Error("failed to produce record",
+					zap.String("topic_name", rec.Topic),
+					zap.Error(err),
+				)
+				return
+			}
+		})
+	}
+
+	return len(records), nil
+}
+
+// frontendEventsTopicConfig returns the default topic configs of all frontend
+// event topics.
+func frontendEventsTopicConfig() map[string]string {
+	return map[string]string{
+		"cleanup.policy":  "delete",
+		"retention.bytes": "3221225472", // 3GiB
+	}
 }
@@ -55,7 +55,7 @@ func (svc *MetaService) Initialize(ctx context.Context) error {
 		AllowHosts("*").
 		ResourcePatternType(kadm.ACLPatternLiteral).
 		Topics("*").
-		Groups("delivery-service").
+		Groups(svc.cfg.GlobalPrefix+"delivery-service").
 		Operations(kadm.OpCreate, kadm.OpDescribe, kadm.OpRead, kadm.OpWrite)
 	if _, err := svc.kafkaAdmCl.CreateACLs(ctx, deliveryServiceACLs); err != nil {
 		svc.logger.Info("failed to create ACLs for delivery-service", zap.Error(err))
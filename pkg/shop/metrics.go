@@ -13,9 +13,23 @@ const (
 	EventTypeCustomerDeleted  = "CUSTOMER_DELETED"
 	EventTypeCustomerConsumed = "CUSTOMER_CONSUMED"
 
-	EventTypeOrderCreated = "ORDER_CREATED"
+	EventTypeOrderCreated  = "ORDER_CREATED"
+	EventTypeOrderUpdated  = "ORDER_UPDATED"
+	EventTypeOrderConsumed = "ORDER_CONSUMED"
+
+	EventTypeShipmentCreated  = "SHIPMENT_CREATED"
+	EventTypeShipmentScanned  = "SHIPMENT_SCANNED"
+	EventTypeShipmentConsumed = "SHIPMENT_CONSUMED"
+
+	EventTypePaymentAuthorized = "PAYMENT_AUTHORIZED"
+	EventTypePaymentDeclined   = "PAYMENT_DECLINED"
+	EventTypePaymentCaptured   = "PAYMENT_CAPTURED"
+	EventTypePaymentRefunded   = "PAYMENT_REFUNDED"
 
 	EventTypeFrontendEventCreated = "FRONTEND_EVENT_CREATED"
+
+	DropReasonMaxInFlight  = "max_in_flight"
+	DropReasonSchedulerLag = "scheduler_lag"
 )
 
 var (
@@ -27,6 +41,33 @@ var (
 		Help:      "The number of page impressions simulated",
 	})
 
+	pageImpressionsInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: promNamespace,
+		Name:      "simulated_impressions_in_flight",
+		Help:      "The number of page impressions that are queued or being executed",
+	})
+	pageImpressionsDroppedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: promNamespace,
+		Name:      "simulated_impressions_dropped_total",
+		Help:      "The number of scheduled page impressions that were dropped, because the shop could not keep up",
+	}, []string{"reason"})
+	pageImpressionsTargetRate = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: promNamespace,
+		Name:      "simulated_impressions_target_rate",
+		Help:      "The number of page impressions per second that shall be simulated",
+	})
+	pageImpressionsAchievedRate = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: promNamespace,
+		Name:      "simulated_impressions_achieved_rate",
+		Help:      "The number of page impressions per second that have actually been simulated",
+	})
+
+	trafficFactor = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: promNamespace,
+		Name:      "traffic_factor",
+		Help:      "The factor by which the configured request rate is currently multiplied by the traffic profile",
+	})
+
 	kafkaMessagesProducedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Namespace: promNamespace,
 		Name:      "kafka_messages_produced_total",
@@ -4,75 +4,154 @@ import (
 	"context"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/hamba/avro/v2"
-	"github.com/twmb/franz-go/pkg/kadm"
 	"github.com/twmb/franz-go/pkg/kgo"
 	"github.com/twmb/franz-go/pkg/sr"
 	"go.uber.org/zap"
-	"google.golang.org/protobuf/proto"
 
 	"github.com/cloudhut/owl-shop/pkg/config"
 	"github.com/cloudhut/owl-shop/pkg/fake"
 	"github.com/cloudhut/owl-shop/pkg/kafka"
 	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
 	embedavro "github.com/cloudhut/owl-shop/pkg/shop/schemas/avro"
+	embedjson "github.com/cloudhut/owl-shop/pkg/shop/schemas/json"
+	"github.com/cloudhut/owl-shop/pkg/sink"
 	embedproto "github.com/cloudhut/owl-shop/proto"
 )
 
+const (
+	// transactionalPollTimeout bounds each poll of the customers topic in
+	// transactional mode, because orders can't be produced while polling.
+	transactionalPollTimeout = 250 * time.Millisecond
+
+	// transactionTimeout is the max duration for producing an order and
+	// committing its transaction.
+	transactionTimeout = 30 * time.Second
+)
+
+// Subjects of the schemas that are referenced by the order schemas. Unlike all
+// other resources, these subjects are not prefixed and are therefore shared by
+// all shops that use the same schema registry.
+const (
+	subjectCustomerProto = "shop/v1/customer.proto"
+	subjectAddressProto  = "shop/v1/address.proto"
+	subjectCustomerAvro  = "com.shop.v1.avro.Customer"
+	subjectAddressAvro   = "com.shop.v1.avro.Address"
+	subjectCustomerJSON  = "shop/v1/customer.json"
+	subjectAddressJSON   = "shop/v1/address.json"
+)
+
+// schemaRegistryClient is the subset of the schema registry API that is used to
+// register the schemas of the serialized records. It is implemented by the
+// franz-go schema registry client as well as by the in-memory registry.
+type schemaRegistryClient interface {
+	CreateSchema(ctx context.Context, subject string, s sr.Schema) (sr.SubjectSchema, error)
+}
+
 // OrderService is the service that is in charge of handling incoming orders.
 // When a new customer order is received this service will produce a message
-// on the order topics in different formats (JSON and Protobuf).
+// on the order topics in different formats (JSON, Protobuf and Avro).
 // Because orders belong to a customer, this service also consumes the customers
-// topic
+// topic. Shipped orders are delivered once the delivered shipment has been
+// consumed from the shipments topic.
 type OrderService struct {
 	cfg    config.Shop
 	logger *zap.Logger
 
-	kafkaFactory   *kafka.Factory
+	generator *fake.Generator
+	sink      sink.Sink
+	srClient  schemaRegistryClient
+	subjects  subjectNamer
+	keys      *recordKeys
+	// consumerClient is nil if records are not written to Kafka. In this case
+	// customers must be passed to HandleCustomerRecord by the caller.
 	consumerClient *kgo.Client
-	metaClient     *kgo.Client
-	srClient       *sr.Client
+	// txSession is only set if orders are produced transactionally. It is
+	// used for consuming customers as well, so that the consumed offsets are
+	// committed within the order transactions. txMu serializes polling and
+	// transactions, because a session must not poll within a transaction.
+	txSession *kgo.GroupTransactSession
+	txMu      sync.Mutex
 
 	bufferSize        int
 	recentCustomersMu sync.RWMutex
 	recentCustomers   []fake.Customer
+	// openOrders are the orders whose status can still change. They are
+	// advanced in the order they have been created or last updated. Shipped
+	// orders wait for their delivery instead, keyed by the order id. Their
+	// delivery may never be consumed (e.g. if shipments are not scanned), so
+	// that the oldest shipped orders are evicted once the buffer is full.
+	openOrdersMu  sync.Mutex
+	openOrders    []fake.Order
+	shippedOrders *fifoMap[fake.Order]
 
 	topicName              string
 	topicNameProtobufPlain string
 	topicNameProtobufSr    string
 	topicNameAvroSr        string
+	topicNameJSONSr        string
 
 	protobufSerde sr.Serde
 	avroSerde     sr.Serde
+	jsonSerde     sr.Serde
 }
 
-// NewOrderService creates a new OrderService. All dependencies are passed into here.
-// The schema registry client is optional and may be nil.
+// NewOrderService creates a new OrderService. All dependencies are passed into
+// here. The Kafka factory is only used for consuming customers and shipments and
+// may be nil if records are not written to Kafka. The schema registry client is
+// optional and may be nil, unless keys are encoded with a schema registry format.
 func NewOrderService(
 	cfg config.Shop,
 	logger *zap.Logger,
 	kafkaFactory *kafka.Factory,
-	srClient *sr.Client,
+	sinkFactory sink.Factory,
+	generator *fake.Generator,
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
 ) (*OrderService, error) {
 	clientID := cfg.GlobalPrefix + "order-service"
 
-	metaClient, err := kafkaFactory.NewKafkaClient(clientID)
+	recordSink, err := sinkFactory.NewSink(clientID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create kafka service: %w", err)
+		return nil, fmt.Errorf("failed to create sink: %w", err)
 	}
 
-	consumerClient, err := kafkaFactory.NewKafkaClient(
-		clientID,
-		kgo.ConsumerGroup(clientID),
-		kgo.ConsumeTopics(cfg.GlobalPrefix+"customers"),
-		kgo.AutoCommitInterval(500*time.Millisecond),
-	)
+	keys, err := newRecordKeys(cfg.Keys, srClient, subjects, "Order", &shoppb.OrderKey{}, embedavro.OrderKeyAvro)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create kafka consumer client: %w", err)
+		return nil, err
+	}
+
+	var consumerClient *kgo.Client
+	var txSession *kgo.GroupTransactSession
+	switch {
+	case kafkaFactory != nil && cfg.TransactionalOrders:
+		txSession, err = kafkaFactory.NewGroupTransactSession(
+			clientID,
+			kgo.ConsumerGroup(clientID),
+			kgo.ConsumeTopics(cfg.Topic(config.ShopTopicCustomers).Name, cfg.Topic(config.ShopTopicShipments).Name),
+			kgo.FetchIsolationLevel(kgo.ReadCommitted()),
+			kgo.RequireStableFetchOffsets(),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kafka transact session: %w", err)
+		}
+		consumerClient = txSession.Client()
+	case kafkaFactory != nil:
+		consumerClient, err = kafkaFactory.NewKafkaClient(
+			clientID,
+			kgo.ConsumerGroup(clientID),
+			kgo.ConsumeTopics(cfg.Topic(config.ShopTopicCustomers).Name, cfg.Topic(config.ShopTopicShipments).Name),
+			kgo.AutoCommitInterval(500*time.Millisecond),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create kafka consumer client: %w", err)
+		}
 	}
 
 	// This slice is used to keep some customers in the buffer so that they can be modified or deleted
@@ -83,96 +162,161 @@ func NewOrderService(
 		cfg:    cfg,
 		logger: logger.With(zap.String("service", "order_service")),
 
-		kafkaFactory:   kafkaFactory,
-		consumerClient: consumerClient,
-		metaClient:     metaClient,
+		generator:      generator,
+		sink:           recordSink,
 		srClient:       srClient,
+		subjects:       subjects,
+		keys:           keys,
+		consumerClient: consumerClient,
+		txSession:      txSession,
 
 		bufferSize:        bufferSize,
 		recentCustomersMu: sync.RWMutex{},
 		recentCustomers:   recentCustomers,
+		shippedOrders:     newFIFOMap[fake.Order](bufferSize),
 
-		topicName:              cfg.GlobalPrefix + "orders",
-		topicNameProtobufPlain: cfg.GlobalPrefix + "orders" + "-protobuf-plain",
-		topicNameProtobufSr:    cfg.GlobalPrefix + "orders" + "-protobuf-sr",
-		topicNameAvroSr:        cfg.GlobalPrefix + "orders" + "-avro-sr",
+		topicName:              cfg.Topic(config.ShopTopicOrders).Name,
+		topicNameProtobufPlain: cfg.Topic(config.ShopTopicOrdersProtobufPlain).Name,
+		topicNameProtobufSr:    cfg.Topic(config.ShopTopicOrdersProtobufSr).Name,
+		topicNameAvroSr:        cfg.Topic(config.ShopTopicOrdersAvroSr).Name,
+		topicNameJSONSr:        cfg.Topic(config.ShopTopicOrdersJsonSr).Name,
 
 		protobufSerde: sr.Serde{}, // Has to be registered after creating the schema
 	}, nil
 }
 
-// Start starts polling for new messages on the customers topic.
-func (svc *OrderService) Start() {
+// Start starts polling for new messages on the customers and shipments topics. It returns
+// once the given context is cancelled or immediately if there is no Kafka consumer.
+func (svc *OrderService) Start(ctx context.Context) {
+	if svc.consumerClient == nil {
+		return
+	}
+
 	for {
-		fetches := svc.consumerClient.PollFetches(context.Background())
+		fetches := svc.pollFetches(ctx)
 
-		errors := fetches.Errors()
-		if errors != nil {
-			svc.logger.Warn("failed to poll fetches", zap.Error(errors[0].Err))
+		if ctx.Err() != nil {
+			return
 		}
 
+		for _, fetchErr := range fetches.Errors() {
+			if errors.Is(fetchErr.Err, context.DeadlineExceeded) {
+				continue
+			}
+			svc.logger.Warn("failed to poll fetches", zap.Error(fetchErr.Err))
+			break
+		}
+
+		shipmentsTopic := svc.cfg.Topic(config.ShopTopicShipments).Name
 		iter := fetches.RecordIter()
 		for !iter.Done() {
 			rec := iter.Next()
-			kafkaMessagesConsumedTotal.With(map[string]string{"event_type": EventTypeCustomerConsumed}).Inc()
-
-			if rec.Value == nil {
-				continue
-			}
-			customer := fake.Customer{}
-			err := json.Unmarshal(rec.Value, &customer)
-			if err != nil {
-				// Skip message
-				svc.logger.Warn("failed to deserialize customer", zap.Error(err))
+			if rec.Topic == shipmentsTopic {
+				svc.HandleShipmentRecord(rec)
 				continue
 			}
-			svc.recentCustomersMu.Lock()
-			if len(svc.recentCustomers) < svc.bufferSize {
-				svc.recentCustomers = append(svc.recentCustomers, customer)
-			}
-			svc.recentCustomersMu.Unlock()
+			svc.HandleCustomerRecord(rec)
 		}
 	}
 }
 
+// pollFetches polls the customers topic. In transactional mode each poll is
+// bounded, so that orders can be produced in between.
+func (svc *OrderService) pollFetches(ctx context.Context) kgo.Fetches {
+	if svc.txSession == nil {
+		return svc.consumerClient.PollFetches(ctx)
+	}
+
+	svc.txMu.Lock()
+	defer svc.txMu.Unlock()
+	pollCtx, cancel := context.WithTimeout(ctx, transactionalPollTimeout)
+	defer cancel()
+
+	return svc.txSession.PollFetches(pollCtx)
+}
+
+// HandleCustomerRecord adds the customer of a record from the customers topic
+// to the buffer, so that orders can be created for that customer.
+func (svc *OrderService) HandleCustomerRecord(rec *kgo.Record) {
+	kafkaMessagesConsumedTotal.With(map[string]string{"event_type": EventTypeCustomerConsumed}).Inc()
+
+	if rec.Value == nil {
+		return
+	}
+	customer := fake.Customer{}
+	err := json.Unmarshal(rec.Value, &customer)
+	if err != nil {
+		// Skip message
+		svc.logger.Warn("failed to deserialize customer", zap.Error(err))
+		return
+	}
+	svc.recentCustomersMu.Lock()
+	if len(svc.recentCustomers) < svc.bufferSize {
+		svc.recentCustomers = append(svc.recentCustomers, customer)
+	}
+	svc.recentCustomersMu.Unlock()
+}
+
+// HandleShipmentRecord delivers the order of a record from the shipments topic,
+// if the shipment has been delivered.
+func (svc *OrderService) HandleShipmentRecord(rec *kgo.Record) {
+	kafkaMessagesConsumedTotal.With(map[string]string{"event_type": EventTypeShipmentConsumed}).Inc()
+
+	if rec.Value == nil {
+		return
+	}
+	shipment := fake.Shipment{}
+	if err := json.Unmarshal(rec.Value, &shipment); err != nil {
+		// Skip message
+		svc.logger.Warn("failed to deserialize shipment", zap.Error(err))
+		return
+	}
+	if !shipment.IsDelivered() {
+		return
+	}
+
+	svc.openOrdersMu.Lock()
+	shipped, exists := svc.shippedOrders.Remove(shipment.OrderID)
+	svc.openOrdersMu.Unlock()
+	if !exists {
+		// The order has been shipped by a previous run of the shop or it has
+		// been evicted
+		return
+	}
+
+	order := svc.generator.DeliverOrder(shipped, shipment.ScannedAt)
+	produced, err := svc.produceOrder(order)
+	if err != nil {
+		return
+	}
+	svc.pushOpenOrder(order)
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeOrderUpdated}).Add(float64(produced))
+}
+
 // Initialize order service by reconciling all order topics.
 func (svc *OrderService) Initialize(ctx context.Context) error {
 	svc.logger.Info("initializing order service")
 
-	topicCfg := map[string]*string{
-		"cleanup.policy": kadm.StringPtr("compact"),
+	topicCfg := map[string]string{
+		"cleanup.policy": "compact",
 	}
-	err := kafka.ReconcileTopic(ctx,
-		svc.metaClient,
-		svc.topicName,
-		svc.cfg.TopicPartitionCount,
-		svc.cfg.TopicReplicationFactor,
-		topicCfg,
-	)
+	err := svc.keys.CreateTopic(ctx, svc.sink, newSinkTopic(svc.cfg, config.ShopTopicOrders, sink.FormatJSON, topicCfg))
 	if err != nil {
 		return fmt.Errorf("failed to create topic: %w", err)
 	}
 
-	err = kafka.ReconcileTopic(ctx,
-		svc.metaClient,
-		svc.topicNameProtobufPlain,
-		svc.cfg.TopicPartitionCount,
-		svc.cfg.TopicReplicationFactor,
-		topicCfg,
-	)
+	protobufPlainTopic := newSinkTopic(svc.cfg, config.ShopTopicOrdersProtobufPlain, sink.FormatProtobuf, topicCfg)
+	protobufPlainTopic.MessageType = (&shoppb.Order{}).ProtoReflect().Type()
+	err = svc.keys.CreateTopic(ctx, svc.sink, protobufPlainTopic)
 	if err != nil {
 		return fmt.Errorf("failed to create protobuf plain topic: %w", err)
 	}
 
 	if svc.srClient != nil {
 		// 1. Protobuf Setup
-		if err := kafka.ReconcileTopic(ctx,
-			svc.metaClient,
-			svc.topicNameProtobufSr,
-			svc.cfg.TopicPartitionCount,
-			svc.cfg.TopicReplicationFactor,
-			topicCfg,
-		); err != nil {
+		protobufSrTopic := newSinkTopic(svc.cfg, config.ShopTopicOrdersProtobufSr, sink.FormatProtobufSR, topicCfg)
+		protobufSrTopic.MessageType = (&shoppb.Order{}).ProtoReflect().Type()
+		if err := svc.keys.CreateTopic(ctx, svc.sink, protobufSrTopic); err != nil {
 			return fmt.Errorf("failed to create protobuf sr topic: %w", err)
 		}
 
@@ -185,19 +329,15 @@ func (svc *OrderService) Initialize(ctx context.Context) error {
 			orderSchemaID,
 			&shoppb.Order{},
 			sr.EncodeFn(func(v any) ([]byte, error) {
-				return proto.Marshal(v.(*shoppb.Order))
+				return protoAPI.Marshal(v.(*shoppb.Order))
 			}),
 			sr.Index(0),
 		)
 
 		// 2. Avro Setup
-		if err := kafka.ReconcileTopic(ctx,
-			svc.metaClient,
-			svc.topicNameAvroSr,
-			svc.cfg.TopicPartitionCount,
-			svc.cfg.TopicReplicationFactor,
-			topicCfg,
-		); err != nil {
+		avroSrTopic := newSinkTopic(svc.cfg, config.ShopTopicOrdersAvroSr, sink.FormatAvroSR, topicCfg)
+		avroSrTopic.Schema = embedavro.OrderAvro
+		if err := svc.keys.CreateTopic(ctx, svc.sink, avroSrTopic); err != nil {
 			return fmt.Errorf("failed to create avro sr topic: %w", err)
 		}
 
@@ -207,9 +347,6 @@ func (svc *OrderService) Initialize(ctx context.Context) error {
 		}
 
 		// Parse all schemas to add them to the global cache
-		avro.DefaultConfig = avro.Config{
-			TagKey: "json",
-		}.Freeze()
 		if _, err := avro.Parse(embedavro.CustomerV2Avro); err != nil {
 			return fmt.Errorf("failed to parse customerV2 avro schema with avro lib: %w", err)
 		}
@@ -225,9 +362,23 @@ func (svc *OrderService) Initialize(ctx context.Context) error {
 			orderAvroSchemaID,
 			fake.Order{},
 			sr.EncodeFn(func(v any) ([]byte, error) {
-				return avro.Marshal(orderAvroSchema, v)
+				return avroAPI.Marshal(orderAvroSchema, v)
 			}),
 		)
+
+		// 3. JSON Schema Setup
+		jsonSrTopic := newSinkTopic(svc.cfg, config.ShopTopicOrdersJsonSr, sink.FormatJSONSR, topicCfg)
+		jsonSrTopic.Schema = embedjson.OrderJSON
+		if err := svc.keys.CreateTopic(ctx, svc.sink, jsonSrTopic); err != nil {
+			return fmt.Errorf("failed to create json sr topic: %w", err)
+		}
+
+		orderJSONSchemaID, err := svc.registerJSONSchema(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to register json schemas in schema registry: %w", err)
+		}
+
+		svc.jsonSerde.Register(orderJSONSchemaID, fake.Order{}, sr.EncodeFn(json.Marshal))
 	}
 
 	return nil
@@ -238,7 +389,8 @@ func (svc *OrderService) Initialize(ctx context.Context) error {
 // If successful, it returns the schema id.
 func (svc *OrderService) registerProtobufSchema(ctx context.Context) (int, error) {
 	// Register dependency schemas first, then main schema with references
-	customerProtoSubject := "shop/v1/customer.proto"
+	customerProtoSubject := svc.subjects.referenceSubject(
+		svc.topicNameProtobufSr, protobufRecordName("Customer"), subjectCustomerProto)
 
 	// This registers an older proto version first, so that we simulate
 	// a schema evolution as well.
@@ -254,7 +406,7 @@ func (svc *OrderService) registerProtobufSchema(ctx context.Context) (int, error
 		return -1, fmt.Errorf("failed to register customer schema: %w", err)
 	}
 
-	_, err = svc.srClient.CreateSchema(
+	customer, err := svc.srClient.CreateSchema(
 		ctx,
 		customerProtoSubject,
 		sr.Schema{
@@ -266,10 +418,9 @@ func (svc *OrderService) registerProtobufSchema(ctx context.Context) (int, error
 		return -1, fmt.Errorf("failed to register customer schema: %w", err)
 	}
 
-	addressProtoSubject := "shop/v1/address.proto"
-	_, err = svc.srClient.CreateSchema(
+	address, err := svc.srClient.CreateSchema(
 		ctx,
-		addressProtoSubject,
+		svc.subjects.referenceSubject(svc.topicNameProtobufSr, protobufRecordName("Address"), subjectAddressProto),
 		sr.Schema{
 			Schema: embedproto.Address,
 			Type:   sr.TypeProtobuf,
@@ -279,22 +430,23 @@ func (svc *OrderService) registerProtobufSchema(ctx context.Context) (int, error
 		return -1, fmt.Errorf("failed to register address schema: %w", err)
 	}
 
+	// Protobuf references are named after the imported file
 	orderSchema, err := svc.srClient.CreateSchema(
 		ctx,
-		svc.topicNameProtobufSr+"-value",
+		svc.subjects.subject(svc.topicNameProtobufSr, protobufRecordName("Order")),
 		sr.Schema{
 			Schema: embedproto.Order,
 			Type:   sr.TypeProtobuf,
 			References: []sr.SchemaReference{
 				{
-					Name:    customerProtoSubject,
-					Subject: customerProtoSubject,
-					Version: 2,
+					Name:    subjectCustomerProto,
+					Subject: customer.Subject,
+					Version: customer.Version,
 				},
 				{
-					Name:    addressProtoSubject,
-					Subject: addressProtoSubject,
-					Version: 1,
+					Name:    subjectAddressProto,
+					Subject: address.Subject,
+					Version: address.Version,
 				},
 			},
 		},
@@ -314,7 +466,7 @@ func (svc *OrderService) registerAvroSchema(ctx context.Context) (int, error) {
 	// a schema evolution as well.
 	customerV1, err := svc.srClient.CreateSchema(
 		ctx,
-		"com.shop.v1.avro.Customer",
+		svc.subjects.referenceSubject(svc.topicNameAvroSr, avroRecordName("Customer"), subjectCustomerAvro),
 		sr.Schema{
 			Schema: embedavro.CustomerV1Avro,
 			Type:   sr.TypeAvro,
@@ -338,7 +490,7 @@ func (svc *OrderService) registerAvroSchema(ctx context.Context) (int, error) {
 
 	address, err := svc.srClient.CreateSchema(
 		ctx,
-		"com.shop.v1.avro.Address",
+		svc.subjects.referenceSubject(svc.topicNameAvroSr, avroRecordName("Address"), subjectAddressAvro),
 		sr.Schema{
 			Schema: embedavro.AddressAvro,
 			Type:   sr.TypeAvro,
@@ -348,22 +500,79 @@ func (svc *OrderService) registerAvroSchema(ctx context.Context) (int, error) {
 		return -1, fmt.Errorf("failed to register address schema: %w", err)
 	}
 
+	// Avro references are named after the fully qualified record name
 	orderSchema, err := svc.srClient.CreateSchema(
 		ctx,
-		svc.topicNameAvroSr+"-value",
+		svc.subjects.subject(svc.topicNameAvroSr, avroRecordName("Order")),
 		sr.Schema{
 			Schema: embedavro.OrderAvro,
 			Type:   sr.TypeAvro,
 			References: []sr.SchemaReference{
 				{
-					Name:    customerV2.Subject,
+					Name:    avroRecordName("Customer"),
 					Subject: customerV2.Subject,
-					Version: 2,
+					Version: customerV2.Version,
 				},
 				{
-					Name:    address.Subject,
+					Name:    avroRecordName("Address"),
 					Subject: address.Subject,
-					Version: 1,
+					Version: address.Version,
+				},
+			},
+		},
+	)
+	if err != nil {
+		return -1, fmt.Errorf("failed to register order schema: %w", err)
+	}
+
+	return orderSchema.ID, nil
+}
+
+// registerJSONSchema registers the used JSON schemas in the schema registry, so that
+// serialized messages can be deserialized by other tools like Redpanda Console or CLIs.
+// The order schema references the customer and address schemas by their file names.
+// If successful, it returns the schema id.
+func (svc *OrderService) registerJSONSchema(ctx context.Context) (int, error) {
+	customer, err := svc.srClient.CreateSchema(
+		ctx,
+		svc.subjects.referenceSubject(svc.topicNameJSONSr, jsonRecordName("Customer"), subjectCustomerJSON),
+		sr.Schema{
+			Schema: embedjson.CustomerJSON,
+			Type:   sr.TypeJSON,
+		},
+	)
+	if err != nil {
+		return -1, fmt.Errorf("failed to register customer schema: %w", err)
+	}
+
+	address, err := svc.srClient.CreateSchema(
+		ctx,
+		svc.subjects.referenceSubject(svc.topicNameJSONSr, jsonRecordName("Address"), subjectAddressJSON),
+		sr.Schema{
+			Schema: embedjson.AddressJSON,
+			Type:   sr.TypeJSON,
+		},
+	)
+	if err != nil {
+		return -1, fmt.Errorf("failed to register address schema: %w", err)
+	}
+
+	orderSchema, err := svc.srClient.CreateSchema(
+		ctx,
+		svc.subjects.subject(svc.topicNameJSONSr, jsonRecordName("Order")),
+		sr.Schema{
+			Schema: embedjson.OrderJSON,
+			Type:   sr.TypeJSON,
+			References: []sr.SchemaReference{
+				{
+					Name:    "customer.json",
+					Subject: customer.Subject,
+					Version: customer.Version,
+				},
+				{
+					Name:    "address.json",
+					Subject: address.Subject,
+					Version: address.Version,
 				},
 			},
 		},
@@ -378,173 +587,356 @@ func (svc *OrderService) registerAvroSchema(ctx context.Context) (int, error) {
 // CreateOrder creates a new fake order message. It pops a previously produced
 // fake customer from the in-memory cache so that an existing customer can be
 // referenced in the order message.
-func (svc *OrderService) CreateOrder() {
+func (svc *OrderService) CreateOrder() error {
 	customer, err := svc.popCustomerFromBuffer()
 	if err != nil {
 		svc.logger.Debug("failed to pop customer from buffer", zap.Error(err))
-		return
+		return err
 	}
-	order := fake.NewOrder(customer)
+	order := svc.generator.NewOrder(customer)
 
-	err = svc.produceOrderJSON(order)
+	produced, err := svc.produceOrder(order)
 	if err != nil {
-		svc.logger.Warn("failed to produce order (json)", zap.Error(err))
-		return
+		return err
 	}
-	err = svc.produceOrderPlainProtobuf(order)
+	svc.pushOpenOrder(order)
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeOrderCreated}).Add(float64(produced))
+
+	return nil
+}
+
+// UpdateOrder takes the next open order, advances it to the next status of its
+// lifecycle and produces the revised order to all order topics. Orders that are
+// still open afterwards are put back, so that they progress further later on.
+// Shipped orders are put aside until their shipment has been delivered.
+func (svc *OrderService) UpdateOrder() error {
+	previous, err := svc.popOpenOrder()
 	if err != nil {
-		svc.logger.Warn("failed to produce order (protobuf)", zap.Error(err))
-		return
+		svc.logger.Debug("failed to pop open order", zap.Error(err))
+		return err
+	}
+	order := svc.generator.AdvanceOrder(previous)
+
+	// The delivery may be consumed before the produce call returns, hence
+	// shipped orders must be put aside beforehand.
+	isShipped := order.Status == fake.OrderStatusShipped
+	if isShipped {
+		svc.openOrdersMu.Lock()
+		evicted, isEvicted := svc.shippedOrders.Put(order.ID, order)
+		svc.openOrdersMu.Unlock()
+		if isEvicted {
+			svc.logger.Debug("evicted shipped order whose delivery has not been consumed",
+				zap.String("order_id", evicted.ID))
+		}
+	}
+
+	produced, err := svc.produceOrder(order)
+	if err != nil {
+		// Put the unchanged order back, so that it can be advanced again later on
+		if isShipped {
+			svc.openOrdersMu.Lock()
+			svc.shippedOrders.Remove(order.ID)
+			svc.openOrdersMu.Unlock()
+		}
+		svc.pushOpenOrder(previous)
+		return err
+	}
+	if order.IsOpen() && !isShipped {
+		svc.pushOpenOrder(order)
+	}
+	svc.logger.Debug("updated order", zap.String("status", string(order.Status)))
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeOrderUpdated}).Add(float64(produced))
+
+	return nil
+}
+
+// produceOrder produces the order to all order topics and returns the number of
+// produced records.
+func (svc *OrderService) produceOrder(order fake.Order) (int, error) {
+	records, err := svc.orderRecords(order)
+	if err != nil {
+		svc.logger.Warn("failed to serialize order", zap.Error(err))
+		return 0, err
+	}
+
+	if svc.txSession != nil {
+		if err := svc.produceTransactional(records); err != nil {
+			svc.logger.Warn("failed to produce order transactionally", zap.Error(err))
+			return 0, err
+		}
+		return len(records), nil
+	}
+
+	for _, rec := range records {
+		svc.sink.Produce(context.Background(), rec, func(rec *kgo.Record, err error) {
+			if err != nil {
+				svc.logger.Error("failed to produce record",
+					zap.String("topic_name", rec.Topic),
+					zap.Error(err),
+				)
+				return
+			}
+		})
+	}
+
+	return len(records), nil
+}
+
+// orderRecords serializes the order for each of the order topics.
+func (svc *OrderService) orderRecords(order fake.Order) ([]*kgo.Record, error) {
+	jsonRec, err := svc.orderJSONRecord(order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create json record: %w", err)
 	}
-	kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeOrderCreated}).Add(2)
+	protobufRec, err := svc.orderPlainProtobufRecord(order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create protobuf record: %w", err)
+	}
+	records := []*kgo.Record{jsonRec, protobufRec}
 
 	if svc.srClient != nil {
-		err = svc.produceOrderSrProtobuf(order)
+		protobufSrRec, err := svc.orderSrProtobufRecord(order)
 		if err != nil {
-			svc.logger.Warn("failed to produce order (protobuf sr)", zap.Error(err))
-			return
+			return nil, fmt.Errorf("failed to create protobuf sr record: %w", err)
 		}
-		kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeOrderCreated}).Add(1)
-
-		err = svc.produceOrderSrAvro(order)
+		avroSrRec, err := svc.orderSrAvroRecord(order)
 		if err != nil {
-			svc.logger.Warn("failed to produce order (avro sr)", zap.Error(err))
-			return
+			return nil, fmt.Errorf("failed to create avro sr record: %w", err)
+		}
+		jsonSrRec, err := svc.orderSrJSONRecord(order)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create json sr record: %w", err)
 		}
-		kafkaMessagesProducedTotal.With(map[string]string{"event_type": EventTypeOrderCreated}).Add(1)
+		records = append(records, protobufSrRec, avroSrRec, jsonSrRec)
+	}
+
+	return records, nil
+}
+
+// produceTransactional produces the records of an order in a single transaction,
+// which also commits the offsets of all customers that have been consumed so far.
+func (svc *OrderService) produceTransactional(records []*kgo.Record) error {
+	svc.txMu.Lock()
+	defer svc.txMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), transactionTimeout)
+	defer cancel()
+
+	if err := svc.txSession.Begin(); err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	produceErr := svc.txSession.ProduceSync(ctx, records...).FirstErr()
+	committed, err := svc.txSession.End(ctx, kgo.TransactionEndTry(produceErr == nil))
+	if produceErr != nil {
+		return fmt.Errorf("failed to produce records, transaction has been aborted: %w", produceErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to end transaction: %w", err)
+	}
+	if !committed {
+		return fmt.Errorf("transaction has been aborted due to a consumer group rebalance")
 	}
 
-	return
+	return nil
 }
 
-func (svc *OrderService) produceOrderJSON(order fake.Order) error {
+func (svc *OrderService) orderJSONRecord(order fake.Order) (*kgo.Record, error) {
 	serialized, err := json.Marshal(order)
 	if err != nil {
-		return fmt.Errorf("failed to serialize customer struct: %w", err)
+		return nil, fmt.Errorf("failed to serialize customer struct: %w", err)
+	}
+
+	key, err := svc.keys.Encode(svc.topicName, order.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	rec := kgo.Record{
-		Key:       []byte(order.ID),
+		Key:       key,
 		Value:     serialized,
-		Headers:   []kgo.RecordHeader{{Key: "revision", Value: []byte("0")}},
-		Timestamp: time.Now(),
+		Headers:   []kgo.RecordHeader{{Key: "revision", Value: []byte(strconv.Itoa(order.Revision))}},
+		Timestamp: order.LastUpdatedAt,
 		Topic:     svc.topicName,
 	}
 
-	svc.metaClient.Produce(context.Background(), &rec, func(rec *kgo.Record, err error) {
-		if err != nil {
-			svc.logger.Error("failed to produce record",
-				zap.String("topic_name", rec.Topic),
-				zap.Error(err),
-			)
-			return
-		}
-	})
-
-	return nil
+	return &rec, nil
 }
 
-func (svc *OrderService) produceOrderPlainProtobuf(order fake.Order) error {
+func (svc *OrderService) orderPlainProtobufRecord(order fake.Order) (*kgo.Record, error) {
 	pbOrder := order.Protobuf()
-	serialized, err := proto.Marshal(pbOrder)
+	serialized, err := protoAPI.Marshal(pbOrder)
 	if err != nil {
-		return fmt.Errorf("failed to serialize customer struct: %w", err)
+		return nil, fmt.Errorf("failed to serialize customer struct: %w", err)
+	}
+
+	key, err := svc.keys.Encode(svc.topicNameProtobufPlain, order.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	rec := kgo.Record{
-		Key:   []byte(order.ID),
+		Key:   key,
 		Value: serialized,
 		Headers: []kgo.RecordHeader{
-			{Key: "revision", Value: []byte("0")},
+			{Key: "revision", Value: []byte(strconv.Itoa(order.Revision))},
 			{Key: "proto_message_type", Value: []byte("Order")},
 		},
-		Timestamp: time.Now(),
+		Timestamp: order.LastUpdatedAt,
 		Topic:     svc.topicNameProtobufPlain,
 	}
 
-	svc.metaClient.Produce(context.Background(), &rec, func(rec *kgo.Record, err error) {
-		if err != nil {
-			svc.logger.Error("failed to produce record",
-				zap.String("topic_name", rec.Topic),
-				zap.Error(err),
-			)
-			return
-		}
-	})
-
-	return nil
+	return &rec, nil
 }
 
-// produceOrderSrProtobuf produces a protobuf message with schema registry encoding.
-func (svc *OrderService) produceOrderSrProtobuf(order fake.Order) error {
+// orderSrProtobufRecord creates a protobuf message with schema registry encoding.
+func (svc *OrderService) orderSrProtobufRecord(order fake.Order) (*kgo.Record, error) {
 	pbOrder := order.Protobuf()
 	serialized, err := svc.protobufSerde.Encode(pbOrder)
 	if err != nil {
-		return fmt.Errorf("failed to encode porotobuf order: %w", err)
+		return nil, fmt.Errorf("failed to encode porotobuf order: %w", err)
+	}
+
+	key, err := svc.keys.Encode(svc.topicNameProtobufSr, order.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	rec := kgo.Record{
-		Key:   []byte(order.ID),
+		Key:   key,
 		Value: serialized,
 		Headers: []kgo.RecordHeader{
-			{Key: "revision", Value: []byte("0")},
+			{Key: "revision", Value: []byte(strconv.Itoa(order.Revision))},
 			{Key: "proto_message_type", Value: []byte("Order")},
 		},
-		Timestamp: time.Now(),
+		Timestamp: order.LastUpdatedAt,
 		Topic:     svc.topicNameProtobufSr,
 	}
 
-	svc.metaClient.Produce(context.Background(), &rec, func(rec *kgo.Record, err error) {
-		if err != nil {
-			svc.logger.Error("failed to produce record",
-				zap.String("topic_name", rec.Topic),
-				zap.Error(err),
-			)
-			return
-		}
-	})
-
-	return nil
+	return &rec, nil
 }
 
-// produceOrderSrAvro produces an avro message with schema registry encoding.
-func (svc *OrderService) produceOrderSrAvro(order fake.Order) error {
+// orderSrAvroRecord creates an avro message with schema registry encoding.
+func (svc *OrderService) orderSrAvroRecord(order fake.Order) (*kgo.Record, error) {
 	serialized, err := svc.avroSerde.Encode(order)
 	if err != nil {
-		return fmt.Errorf("failed to encode avro order: %w", err)
+		return nil, fmt.Errorf("failed to encode avro order: %w", err)
+	}
+
+	key, err := svc.keys.Encode(svc.topicNameAvroSr, order.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	rec := kgo.Record{
-		Key:   []byte(order.ID),
+		Key:   key,
 		Value: serialized,
 		Headers: []kgo.RecordHeader{
-			{Key: "revision", Value: []byte("0")},
+			{Key: "revision", Value: []byte(strconv.Itoa(order.Revision))},
 			{Key: "avro_message_type", Value: []byte("Order")},
 		},
-		Timestamp: time.Now(),
+		Timestamp: order.LastUpdatedAt,
 		Topic:     svc.topicNameAvroSr,
 	}
 
-	svc.metaClient.Produce(context.Background(), &rec, func(rec *kgo.Record, err error) {
-		if err != nil {
-			svc.logger.Error("failed to produce record",
-				zap.String("topic_name", rec.Topic),
-				zap.Error(err),
-			)
-			return
+	return &rec, nil
+}
+
+// orderSrJSONRecord creates a JSON message with schema registry encoding.
+func (svc *OrderService) orderSrJSONRecord(order fake.Order) (*kgo.Record, error) {
+	serialized, err := svc.jsonSerde.Encode(order)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode json order: %w", err)
+	}
+
+	key, err := svc.keys.Encode(svc.topicNameJSONSr, order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := kgo.Record{
+		Key:   key,
+		Value: serialized,
+		Headers: []kgo.RecordHeader{
+			{Key: "revision", Value: []byte(strconv.Itoa(order.Revision))},
+			{Key: "json_message_type", Value: []byte("Order")},
+		},
+		Timestamp: order.LastUpdatedAt,
+		Topic:     svc.topicNameJSONSr,
+	}
+
+	return &rec, nil
+}
+
+// Close commits the consumed offsets, flushes all buffered order records and
+// closes the sink and Kafka clients. Start must have returned before calling Close.
+func (svc *OrderService) Close(ctx context.Context) error {
+	defer svc.sink.Close()
+	switch {
+	case svc.txSession != nil:
+		defer svc.txSession.Close()
+	case svc.consumerClient != nil:
+		defer svc.consumerClient.Close()
+	}
+
+	// Offsets are only committed once the records produced for the consumed
+	// records have been flushed, so that none of them are lost.
+	if err := svc.sink.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush buffered records: %w", err)
+	}
+
+	switch {
+	case svc.txSession != nil:
+		// Offsets of transactional consumers can only be committed within a
+		// transaction, hence an empty transaction is used to commit them.
+		svc.txMu.Lock()
+		defer svc.txMu.Unlock()
+		if err := svc.txSession.Begin(); err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		if _, err := svc.txSession.End(ctx, kgo.TryCommit); err != nil {
+			return fmt.Errorf("failed to commit offsets: %w", err)
 		}
-	})
+	case svc.consumerClient != nil:
+		if err := svc.consumerClient.CommitUncommittedOffsets(ctx); err != nil {
+			return fmt.Errorf("failed to commit offsets: %w", err)
+		}
+	}
 
 	return nil
 }
 
+// pushOpenOrder adds the order to the open orders, unless the buffer is full.
+func (svc *OrderService) pushOpenOrder(order fake.Order) {
+	svc.openOrdersMu.Lock()
+	defer svc.openOrdersMu.Unlock()
+
+	if len(svc.openOrders) < svc.bufferSize {
+		svc.openOrders = append(svc.openOrders, order)
+	}
+}
+
+func (svc *OrderService) popOpenOrder() (fake.Order, error) {
+	svc.openOrdersMu.Lock()
+	defer svc.openOrdersMu.Unlock()
+
+	if len(svc.openOrders) == 0 {
+		// No open orders in buffer yet
+		return fake.Order{}, errBufferEmpty
+	}
+	order := svc.openOrders[0]
+	svc.openOrders = svc.openOrders[1:]
+
+	return order, nil
+}
+
 func (svc *OrderService) popCustomerFromBuffer() (fake.Customer, error) {
 	svc.recentCustomersMu.Lock()
 	defer svc.recentCustomersMu.Unlock()
 
 	if len(svc.recentCustomers) == 0 {
 		// No customers in buffer yet
-		return fake.Customer{}, fmt.Errorf("buffer is empty")
+		return fake.Customer{}, errBufferEmpty
 	}
 	customer := svc.recentCustomers[0]
 	svc.recentCustomers = svc.recentCustomers[1:]
@@ -0,0 +1,89 @@
+package shop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/fake"
+)
+
+func newTestOrderService(t *testing.T, generator *fake.Generator) *OrderService {
+	t.Helper()
+
+	var cfg config.Shop
+	cfg.SetDefaults()
+	svc, _ := newTestService(t, cfg, generator, NewOrderService)
+
+	return svc
+}
+
+func TestOrderServiceUndeliveredOrdersDontBlockLifecycle(t *testing.T) {
+	generator := fake.NewGenerator(1)
+	svc := newTestOrderService(t, generator)
+	svc.bufferSize = 2
+	svc.shippedOrders = newFIFOMap[fake.Order](2)
+
+	// Without deliveries, all orders end up shipped or cancelled
+	advanceAll := func() {
+		for i := 0; i < 100; i++ {
+			if err := svc.UpdateOrder(); err != nil {
+				return
+			}
+		}
+		t.Fatalf("orders are still open after 100 updates")
+	}
+	customer := generator.NewCustomer()
+	for i := 0; i < 2; i++ {
+		svc.pushOpenOrder(generator.NewOrder(customer))
+	}
+	advanceAll()
+	for i := 0; i < 2; i++ {
+		svc.pushOpenOrder(generator.NewOrder(customer))
+	}
+	if len(svc.openOrders) != 2 {
+		t.Fatalf("got %d open orders, want 2, shipped orders must not block new orders", len(svc.openOrders))
+	}
+	advanceAll()
+	if svc.shippedOrders.Len() > 2 {
+		t.Errorf("got %d shipped orders, want at most 2", svc.shippedOrders.Len())
+	}
+}
+
+func TestOrderServiceDeliversShippedOrder(t *testing.T) {
+	generator := fake.NewGenerator(1)
+	svc := newTestOrderService(t, generator)
+
+	order := generator.NewOrder(generator.NewCustomer())
+	order.Status = fake.OrderStatusPaid
+	svc.pushOpenOrder(order)
+	// Paid orders are shipped or cancelled
+	if err := svc.UpdateOrder(); err != nil {
+		t.Fatalf("UpdateOrder() error = %v", err)
+	}
+	shipped, exists := svc.shippedOrders.Get(order.ID)
+	if !exists {
+		t.Skip("order has been cancelled")
+	}
+
+	deliveredAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	shipment := fake.Shipment{OrderID: order.ID, Status: fake.ShipmentStatusDelivered, ScannedAt: deliveredAt}
+	svc.HandleShipmentRecord(jsonRecord(t, shipment))
+
+	if _, exists := svc.shippedOrders.Get(order.ID); exists {
+		t.Errorf("delivered order is still waiting for its delivery")
+	}
+	delivered, err := svc.popOpenOrder()
+	if err != nil {
+		t.Fatalf("delivered order has not been put back: %v", err)
+	}
+	if delivered.Status != fake.OrderStatusDelivered {
+		t.Errorf("order status = %v, want %v", delivered.Status, fake.OrderStatusDelivered)
+	}
+	if delivered.DeliveredAt == nil || !delivered.DeliveredAt.Equal(deliveredAt) {
+		t.Errorf("order delivered at %v, want %v", delivered.DeliveredAt, deliveredAt)
+	}
+	if delivered.Revision != shipped.Revision+1 {
+		t.Errorf("order revision = %v, want %v", delivered.Revision, shipped.Revision+1)
+	}
+}
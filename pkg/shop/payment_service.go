@@ -0,0 +1,281 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/fake"
+	"github.com/cloudhut/owl-shop/pkg/kafka"
+	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
+	embedavro "github.com/cloudhut/owl-shop/pkg/shop/schemas/avro"
+	"github.com/cloudhut/owl-shop/pkg/sink"
+)
+
+// PaymentService consumes the orders topic and processes the payment of each
+// order. Each event of a payment's lifecycle is produced to the payments topic:
+// new orders are authorized or declined, paid orders are captured and cancelled
+// or returned orders are refunded.
+type PaymentService struct {
+	cfg       config.Shop
+	logger    *zap.Logger
+	generator *fake.Generator
+
+	sink sink.Sink
+	keys *recordKeys
+	// consumerClient is nil if records are not written to Kafka. In this case
+	// orders must be passed to HandleOrderRecord by the caller.
+	consumerClient *kgo.Client
+
+	// paymentsMu guards the latest event of each payment, keyed by the order id.
+	// Payments are removed once their order has been closed. If the buffer is
+	// full, the oldest payment is evicted, because orders that are dropped by
+	// the order service are never closed.
+	paymentsMu sync.Mutex
+	payments   *fifoMap[fake.Payment]
+
+	topicName string
+}
+
+// NewPaymentService creates the service that publishes payment events to the
+// payments topic. The Kafka factory is only used for consuming orders and may be
+// nil if records are not written to Kafka. The schema registry client may be
+// nil, unless keys are encoded with a schema registry format.
+func NewPaymentService(
+	cfg config.Shop,
+	logger *zap.Logger,
+	kafkaFactory *kafka.Factory,
+	sinkFactory sink.Factory,
+	generator *fake.Generator,
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
+) (*PaymentService, error) {
+	clientID := cfg.GlobalPrefix + "payment-service"
+
+	keys, err := newRecordKeys(cfg.Keys, srClient, subjects, "Payment", &shoppb.PaymentKey{}, embedavro.PaymentKeyAvro)
+	if err != nil {
+		return nil, err
+	}
+
+	var consumerClient *kgo.Client
+	if kafkaFactory != nil {
+		opts := []kgo.Opt{
+			kgo.ConsumeTopics(cfg.Topic(config.ShopTopicOrders).Name),
+			kgo.ConsumerGroup(clientID),
+			kgo.AutoCommitInterval(500 * time.Millisecond),
+		}
+		if cfg.TransactionalOrders {
+			// Orders of aborted transactions must not be processed
+			opts = append(opts, kgo.FetchIsolationLevel(kgo.ReadCommitted()))
+		}
+		consumerClient, err = kafkaFactory.NewKafkaClient(clientID, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create consumer client: %w", err)
+		}
+	}
+
+	recordSink, err := sinkFactory.NewSink(clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sink: %w", err)
+	}
+
+	return &PaymentService{
+		cfg:       cfg,
+		logger:    logger.With(zap.String("service", "payment_service")),
+		generator: generator,
+
+		sink:           recordSink,
+		keys:           keys,
+		consumerClient: consumerClient,
+
+		payments: newFIFOMap[fake.Payment](1000),
+
+		topicName: cfg.Topic(config.ShopTopicPayments).Name,
+	}, nil
+}
+
+// Initialize payment service by reconciling the payments topic.
+func (svc *PaymentService) Initialize(ctx context.Context) error {
+	svc.logger.Info("initializing payment service")
+
+	err := svc.keys.CreateTopic(ctx, svc.sink, newSinkTopic(svc.cfg, config.ShopTopicPayments, sink.FormatJSON, map[string]string{
+		"cleanup.policy": "delete",
+	}))
+	if err != nil {
+		return fmt.Errorf("failed to reconcile topic: %w", err)
+	}
+
+	return nil
+}
+
+// Start consuming orders. It returns once the given context is cancelled or
+// immediately if there is no Kafka consumer.
+func (svc *PaymentService) Start(ctx context.Context) {
+	if svc.consumerClient == nil {
+		return
+	}
+
+	for {
+		fetches := svc.consumerClient.PollFetches(ctx)
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		if fetches.IsClientClosed() {
+			svc.logger.Warn("client closed")
+			return
+		}
+
+		fetches.EachError(func(topic string, partition int32, err error) {
+			svc.logger.Error("failed to poll fetches",
+				zap.String("topic", topic),
+				zap.Int32("partition", partition),
+				zap.Error(err))
+		})
+
+		fetches.EachRecord(svc.HandleOrderRecord)
+	}
+}
+
+// HandleOrderRecord produces the payment events that follow from the status of
+// the order of a record from the orders topic.
+func (svc *PaymentService) HandleOrderRecord(rec *kgo.Record) {
+	kafkaMessagesConsumedTotal.With(map[string]string{"event_type": EventTypeOrderConsumed}).Inc()
+
+	if rec.Value == nil {
+		return
+	}
+	order := fake.Order{}
+	if err := json.Unmarshal(rec.Value, &order); err != nil {
+		// Skip message
+		svc.logger.Warn("failed to deserialize order", zap.Error(err))
+		return
+	}
+
+	if order.Status == fake.OrderStatusCreated {
+		payment := svc.generator.AuthorizePayment(order, svc.cfg.Payments.FailureRates[order.Payment.Method])
+		if svc.producePayment(payment) {
+			svc.putPayment(payment)
+		}
+		return
+	}
+
+	svc.paymentsMu.Lock()
+	payment, exists := svc.payments.Get(order.ID)
+	svc.paymentsMu.Unlock()
+	if !exists {
+		// The order has been created by a previous run of the shop or its
+		// payment has been evicted
+		return
+	}
+
+	switch order.Status {
+	case fake.OrderStatusPaid:
+		// Declined payments have been retried by the customer
+		if payment.Status == fake.PaymentStatusDeclined {
+			payment = svc.generator.ReauthorizePayment(payment)
+			if !svc.producePayment(payment) {
+				return
+			}
+		}
+		payment = svc.generator.CapturePayment(payment)
+		if svc.producePayment(payment) {
+			svc.putPayment(payment)
+		}
+	case fake.OrderStatusCancelled, fake.OrderStatusReturned:
+		// Uncaptured authorizations simply expire
+		if payment.Status == fake.PaymentStatusCaptured {
+			svc.producePayment(svc.generator.RefundPayment(payment))
+		}
+		svc.removePayment(order.ID)
+	case fake.OrderStatusCompleted:
+		svc.removePayment(order.ID)
+	}
+}
+
+// producePayment produces the payment event to the payments topic. It returns
+// false if the payment could not be serialized or its key could not be encoded.
+func (svc *PaymentService) producePayment(payment fake.Payment) bool {
+	serialized, err := json.Marshal(payment)
+	if err != nil {
+		svc.logger.Warn("failed to serialize payment struct", zap.Error(err))
+		return false
+	}
+
+	key, err := svc.keys.Encode(svc.topicName, payment.ID)
+	if err != nil {
+		svc.logger.Warn("failed to encode payment key", zap.Error(err))
+		return false
+	}
+
+	rec := &kgo.Record{
+		Key:       key,
+		Value:     serialized,
+		Headers:   []kgo.RecordHeader{{Key: "revision", Value: []byte(strconv.Itoa(payment.Revision))}},
+		Timestamp: payment.ProcessedAt,
+		Topic:     svc.topicName,
+	}
+	svc.sink.Produce(context.Background(), rec, func(rec *kgo.Record, err error) {
+		if err != nil {
+			svc.logger.Error("failed to produce record",
+				zap.String("topic_name", rec.Topic),
+				zap.Error(err),
+			)
+		}
+	})
+	kafkaMessagesProducedTotal.With(map[string]string{"event_type": paymentEventTypes[payment.Status]}).Inc()
+
+	return true
+}
+
+// paymentEventTypes maps the payment status to the event type of the metrics.
+var paymentEventTypes = map[fake.PaymentStatus]string{
+	fake.PaymentStatusAuthorized: EventTypePaymentAuthorized,
+	fake.PaymentStatusDeclined:   EventTypePaymentDeclined,
+	fake.PaymentStatusCaptured:   EventTypePaymentCaptured,
+	fake.PaymentStatusRefunded:   EventTypePaymentRefunded,
+}
+
+// Close commits the consumed offsets, flushes all buffered payment records and
+// closes the Kafka consumer and the sink. Start must have returned before calling Close.
+func (svc *PaymentService) Close(ctx context.Context) error {
+	defer svc.sink.Close()
+	if svc.consumerClient != nil {
+		defer svc.consumerClient.Close()
+	}
+
+	if err := svc.sink.Flush(ctx); err != nil {
+		return fmt.Errorf("failed to flush buffered records: %w", err)
+	}
+
+	if svc.consumerClient != nil {
+		if err := svc.consumerClient.CommitUncommittedOffsets(ctx); err != nil {
+			return fmt.Errorf("failed to commit offsets: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// putPayment stores the latest event of the payment.
+func (svc *PaymentService) putPayment(payment fake.Payment) {
+	svc.paymentsMu.Lock()
+	defer svc.paymentsMu.Unlock()
+
+	svc.payments.Put(payment.OrderID, payment)
+}
+
+func (svc *PaymentService) removePayment(orderID string) {
+	svc.paymentsMu.Lock()
+	defer svc.paymentsMu.Unlock()
+
+	svc.payments.Remove(orderID)
+}
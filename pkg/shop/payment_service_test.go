@@ -0,0 +1,86 @@
+package shop
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/fake"
+)
+
+func TestPaymentServiceFollowsOrderLifecycle(t *testing.T) {
+	type wantPayment struct {
+		status  fake.PaymentStatus
+		attempt int
+	}
+	tests := []struct {
+		name          string
+		failureRate   float64
+		orderStatuses []fake.OrderStatus
+		want          []wantPayment
+	}{
+		{
+			name:        "authorized, captured and refunded",
+			failureRate: 0,
+			orderStatuses: []fake.OrderStatus{
+				fake.OrderStatusCreated,
+				fake.OrderStatusPaid,
+				fake.OrderStatusCancelled,
+				// The payment has been removed with the closed order
+				fake.OrderStatusCancelled,
+			},
+			want: []wantPayment{
+				{status: fake.PaymentStatusAuthorized, attempt: 1},
+				{status: fake.PaymentStatusCaptured, attempt: 1},
+				{status: fake.PaymentStatusRefunded, attempt: 1},
+			},
+		},
+		{
+			name:          "declined, reauthorized and captured",
+			failureRate:   1,
+			orderStatuses: []fake.OrderStatus{fake.OrderStatusCreated, fake.OrderStatusPaid},
+			want: []wantPayment{
+				{status: fake.PaymentStatusDeclined, attempt: 1},
+				{status: fake.PaymentStatusAuthorized, attempt: 2},
+				{status: fake.PaymentStatusCaptured, attempt: 2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cfg config.Shop
+			cfg.SetDefaults()
+			cfg.Keys.Format = config.KeyFormatJSON
+			for method := range cfg.Payments.FailureRates {
+				cfg.Payments.FailureRates[method] = tt.failureRate
+			}
+			generator := fake.NewGenerator(1)
+			svc, stdout := newTestService(t, cfg, generator, NewPaymentService)
+			produced := collectRecords(stdout, svc.topicName)
+
+			order := generator.NewOrder(generator.NewCustomer())
+			for _, status := range tt.orderStatuses {
+				order.Status = status
+				svc.HandleOrderRecord(jsonRecord(t, order))
+			}
+
+			if len(*produced) != len(tt.want) {
+				t.Fatalf("got %d payment records, want %d", len(*produced), len(tt.want))
+			}
+			wantKey := `{"id":"` + order.Payment.PaymentID + `","tenant":"owlshop"}`
+			for i, rec := range *produced {
+				var payment fake.Payment
+				if err := json.Unmarshal(rec.Value, &payment); err != nil {
+					t.Fatalf("failed to deserialize payment: %v", err)
+				}
+				if payment.Status != tt.want[i].status || payment.Attempt != tt.want[i].attempt {
+					t.Errorf("payment %d status = %v, attempt = %v, want %v, %v",
+						i, payment.Status, payment.Attempt, tt.want[i].status, tt.want[i].attempt)
+				}
+				if string(rec.Key) != wantKey {
+					t.Errorf("payment %d key = %s, want %v", i, rec.Key, wantKey)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hamba/avro/v2"
+	"github.com/twmb/franz-go/pkg/sr"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/sink"
+	embedproto "github.com/cloudhut/owl-shop/proto"
+)
+
+// recordKey is the structured key of an entity's records.
+type recordKey struct {
+	ID     string `json:"id"`
+	Tenant string `json:"tenant"`
+}
+
+// recordKeys encodes the record keys of an entity's topics in the configured
+// key format. Key schemas are registered per topic, hence keys are encoded per
+// topic as well. A nil recordKeys encodes all keys as null.
+type recordKeys struct {
+	format   string
+	tenant   string
+	srClient schemaRegistryClient
+	subjects subjectNamer
+	// messageType is the name of the entity, the key record is named
+	// '<messageType>Key'.
+	messageType string
+
+	protobufType   protoreflect.MessageType
+	avroSchema     string
+	avroSchemaType avro.Schema
+
+	// serdes are keyed by topic name. They are only registered while the
+	// topics are created, hence they can be read concurrently afterwards.
+	serdes map[string]*sr.Serde
+}
+
+// newRecordKeys creates the key encoder of an entity. The protobuf key must be
+// a message of the embedded key.proto and the Avro schema must define the
+// same key record. The schema registry client may be nil, unless keys are
+// encoded with a schema registry format.
+func newRecordKeys(
+	cfg config.ShopKeys,
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
+	messageType string,
+	protobufKey proto.Message,
+	avroSchema string,
+) (*recordKeys, error) {
+	if cfg.IsSchemaRegistryFormat() && srClient == nil {
+		return nil, fmt.Errorf("key format '%v' requires a schema registry", cfg.Format)
+	}
+
+	keys := &recordKeys{
+		format:       cfg.Format,
+		tenant:       cfg.Tenant,
+		srClient:     srClient,
+		subjects:     subjects,
+		messageType:  messageType,
+		protobufType: protobufKey.ProtoReflect().Type(),
+		avroSchema:   avroSchema,
+		serdes:       make(map[string]*sr.Serde),
+	}
+	if cfg.Format == config.KeyFormatAvroSR {
+		schema, err := avro.Parse(avroSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse avro key schema: %w", err)
+		}
+		keys.avroSchemaType = schema
+	}
+
+	return keys, nil
+}
+
+// CreateTopic creates the topic with the configured key format and registers
+// the topic's key schema if necessary.
+func (k *recordKeys) CreateTopic(ctx context.Context, recordSink sink.Sink, topic sink.Topic) error {
+	if k == nil {
+		return recordSink.CreateTopic(ctx, topic)
+	}
+
+	switch k.format {
+	case config.KeyFormatString:
+		topic.KeyFormat = sink.FormatString
+	case config.KeyFormatJSON:
+		topic.KeyFormat = sink.FormatJSON
+	case config.KeyFormatAvroSR:
+		topic.KeyFormat = sink.FormatAvroSR
+		topic.KeySchema = k.avroSchema
+		if err := k.registerAvroSchema(ctx, topic.Name); err != nil {
+			return err
+		}
+	case config.KeyFormatProtobufSR:
+		topic.KeyFormat = sink.FormatProtobufSR
+		topic.KeyMessageType = k.protobufType
+		if err := k.registerProtobufSchema(ctx, topic.Name); err != nil {
+			return err
+		}
+	}
+
+	return recordSink.CreateTopic(ctx, topic)
+}
+
+func (k *recordKeys) registerAvroSchema(ctx context.Context, topic string) error {
+	recordName := avroRecordName(k.messageType + "Key")
+	keySchema, err := k.srClient.CreateSchema(ctx, k.subjects.keySubject(topic, recordName), sr.Schema{
+		Schema: k.avroSchema,
+		Type:   sr.TypeAvro,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register avro key schema: %w", err)
+	}
+
+	var serde sr.Serde
+	serde.Register(keySchema.ID, recordKey{}, sr.EncodeFn(func(v any) ([]byte, error) {
+		return avroAPI.Marshal(k.avroSchemaType, v)
+	}))
+	k.serdes[topic] = &serde
+
+	return nil
+}
+
+func (k *recordKeys) registerProtobufSchema(ctx context.Context, topic string) error {
+	recordName := protobufRecordName(k.messageType + "Key")
+	keySchema, err := k.srClient.CreateSchema(ctx, k.subjects.keySubject(topic, recordName), sr.Schema{
+		Schema: embedproto.Key,
+		Type:   sr.TypeProtobuf,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register protobuf key schema: %w", err)
+	}
+
+	var serde sr.Serde
+	serde.Register(
+		keySchema.ID,
+		k.protobufType.Zero().Interface(),
+		sr.EncodeFn(func(v any) ([]byte, error) {
+			return protoAPI.Marshal(v.(proto.Message))
+		}),
+		sr.Index(k.protobufType.Descriptor().Index()),
+	)
+	k.serdes[topic] = &serde
+
+	return nil
+}
+
+// Encode returns the key of the entity with the given id for the given topic.
+func (k *recordKeys) Encode(topic, id string) ([]byte, error) {
+	if k == nil {
+		return nil, nil
+	}
+
+	key := recordKey{ID: id, Tenant: k.tenant}
+	switch k.format {
+	case config.KeyFormatJSON:
+		return json.Marshal(key)
+	case config.KeyFormatAvroSR:
+		return k.encodeSchemaRegistry(topic, key)
+	case config.KeyFormatProtobufSR:
+		msg := k.protobufType.New()
+		fields := msg.Descriptor().Fields()
+		msg.Set(fields.ByName("id"), protoreflect.ValueOfString(key.ID))
+		msg.Set(fields.ByName("tenant"), protoreflect.ValueOfString(key.Tenant))
+		return k.encodeSchemaRegistry(topic, msg.Interface())
+	}
+
+	return []byte(id), nil
+}
+
+func (k *recordKeys) encodeSchemaRegistry(topic string, key any) ([]byte, error) {
+	serde, exists := k.serdes[topic]
+	if !exists {
+		return nil, fmt.Errorf("key schema of topic '%v' has not been registered", topic)
+	}
+	encoded, err := serde.Encode(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode key: %w", err)
+	}
+
+	return encoded, nil
+}
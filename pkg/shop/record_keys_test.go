@@ -0,0 +1,140 @@
+package shop
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/hamba/avro/v2"
+	franzsr "github.com/twmb/franz-go/pkg/sr"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	shoppb "github.com/cloudhut/owl-shop/pkg/protogen/shop/v1"
+	embedavro "github.com/cloudhut/owl-shop/pkg/shop/schemas/avro"
+	"github.com/cloudhut/owl-shop/pkg/sink"
+	owlsr "github.com/cloudhut/owl-shop/pkg/sr"
+)
+
+func newTestRecordKeys(t *testing.T, format string, srClient schemaRegistryClient) *recordKeys {
+	t.Helper()
+
+	keys, err := newRecordKeys(
+		config.ShopKeys{Format: format, Tenant: "owlshop"},
+		srClient,
+		newSubjectNamer(config.SchemaRegistry{SubjectNameStrategy: config.SubjectNameStrategyTopicName}),
+		"Order",
+		&shoppb.OrderKey{},
+		embedavro.OrderKeyAvro,
+	)
+	if err != nil {
+		t.Fatalf("newRecordKeys() error = %v", err)
+	}
+	stdout, err := sink.NewStdout(io.Discard, sink.StdoutFormatJSON)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	if err := keys.CreateTopic(context.Background(), stdout, sink.Topic{Name: "orders"}); err != nil {
+		t.Fatalf("CreateTopic() error = %v", err)
+	}
+
+	return keys
+}
+
+func TestRecordKeysEncode(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{format: config.KeyFormatString, want: "order-1"},
+		{format: config.KeyFormatJSON, want: `{"id":"order-1","tenant":"owlshop"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			keys := newTestRecordKeys(t, tt.format, nil)
+			key, err := keys.Encode("orders", "order-1")
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			if string(key) != tt.want {
+				t.Errorf("Encode() = %s, want %v", key, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordKeysEncodeAvroSR(t *testing.T) {
+	keys := newTestRecordKeys(t, config.KeyFormatAvroSR, owlsr.NewInMemoryRegistry())
+	key, err := keys.Encode("orders", "order-1")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var header franzsr.ConfluentHeader
+	_, payload, err := header.DecodeID(key)
+	if err != nil {
+		t.Fatalf("key is not framed with a schema id: %v", err)
+	}
+	var decoded recordKey
+	if err := avroAPI.Unmarshal(avro.MustParse(embedavro.OrderKeyAvro), payload, &decoded); err != nil {
+		t.Fatalf("failed to decode key: %v", err)
+	}
+	if want := (recordKey{ID: "order-1", Tenant: "owlshop"}); decoded != want {
+		t.Errorf("decoded key = %+v, want %+v", decoded, want)
+	}
+
+	if _, err := keys.Encode("customers", "order-1"); err == nil {
+		t.Errorf("Encode() error = nil for a topic without registered key schema")
+	}
+}
+
+func TestRecordKeysEncodeProtobufSR(t *testing.T) {
+	keys := newTestRecordKeys(t, config.KeyFormatProtobufSR, owlsr.NewInMemoryRegistry())
+	key, err := keys.Encode("orders", "order-1")
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	var header franzsr.ConfluentHeader
+	_, payload, err := header.DecodeID(key)
+	if err != nil {
+		t.Fatalf("key is not framed with a schema id: %v", err)
+	}
+	index, payload, err := header.DecodeIndex(payload, 8)
+	if err != nil {
+		t.Fatalf("key is not framed with a message index: %v", err)
+	}
+	wantIndex := (&shoppb.OrderKey{}).ProtoReflect().Descriptor().Index()
+	if len(index) != 1 || index[0] != wantIndex {
+		t.Errorf("message index = %v, want [%v]", index, wantIndex)
+	}
+	var decoded shoppb.OrderKey
+	if err := proto.Unmarshal(payload, &decoded); err != nil {
+		t.Fatalf("failed to decode key: %v", err)
+	}
+	if decoded.GetId() != "order-1" || decoded.GetTenant() != "owlshop" {
+		t.Errorf("decoded key = %v, want id order-1 and tenant owlshop", &decoded)
+	}
+}
+
+func TestNewRecordKeysRequiresSchemaRegistry(t *testing.T) {
+	_, err := newRecordKeys(
+		config.ShopKeys{Format: config.KeyFormatAvroSR},
+		nil,
+		newSubjectNamer(config.SchemaRegistry{}),
+		"Order",
+		&shoppb.OrderKey{},
+		embedavro.OrderKeyAvro,
+	)
+	if err == nil {
+		t.Errorf("newRecordKeys() error = nil without schema registry client")
+	}
+}
+
+func TestNilRecordKeysEncodeNull(t *testing.T) {
+	var keys *recordKeys
+	key, err := keys.Encode("frontend-events", "event-1")
+	if err != nil || key != nil {
+		t.Errorf("Encode() = %v, %v, want nil, nil", key, err)
+	}
+}
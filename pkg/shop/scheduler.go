@@ -0,0 +1,122 @@
+package shop
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// schedulerTickInterval is the interval in which the scheduler takes tokens from
+// the token bucket and submits page impressions to the worker pool.
+const schedulerTickInterval = 10 * time.Millisecond
+
+// workerPool executes simulated page impressions with a fixed number of workers.
+// The number of page impressions that are queued or being executed is limited,
+// so that a slow Kafka cluster can not cause an unbounded number of pending
+// page impressions.
+type workerPool struct {
+	maxInFlight int64
+	execute     func()
+
+	jobs     chan struct{}
+	workerWg sync.WaitGroup
+	// stopped is closed once the pool has been closed. Queued page impressions
+	// are dropped afterwards.
+	stopped chan struct{}
+
+	// inFlight tracks all submitted page impressions that have not been executed yet.
+	inFlight      sync.WaitGroup
+	inFlightCount atomic.Int64
+	executedCount atomic.Int64
+}
+
+func newWorkerPool(workers int, maxInFlight int, execute func()) *workerPool {
+	pool := &workerPool{
+		maxInFlight: int64(maxInFlight),
+		execute:     execute,
+		jobs:        make(chan struct{}, maxInFlight),
+		stopped:     make(chan struct{}),
+	}
+
+	pool.workerWg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go pool.work()
+	}
+
+	return pool
+}
+
+func (p *workerPool) work() {
+	defer p.workerWg.Done()
+	for range p.jobs {
+		select {
+		case <-p.stopped:
+		default:
+			p.execute()
+			p.executedCount.Add(1)
+		}
+		p.inFlightCount.Add(-1)
+		pageImpressionsInFlight.Dec()
+		p.inFlight.Done()
+	}
+}
+
+// Submit queues a page impression for execution. It returns false without
+// blocking if the max in-flight limit has been reached. Submit must not be
+// called concurrently or after Close.
+func (p *workerPool) Submit() bool {
+	if p.inFlightCount.Load() >= p.maxInFlight {
+		return false
+	}
+
+	p.inFlightCount.Add(1)
+	pageImpressionsInFlight.Inc()
+	p.inFlight.Add(1)
+	p.jobs <- struct{}{}
+
+	return true
+}
+
+// Executed returns the total number of executed page impressions.
+func (p *workerPool) Executed() int64 {
+	return p.executedCount.Load()
+}
+
+// Close waits until all in-flight page impressions have been executed or the
+// context is done and then stops all workers. Page impressions that are still
+// queued once the context is done are dropped, page impressions that are being
+// executed are not interrupted.
+func (p *workerPool) Close(ctx context.Context) error {
+	err := waitWithContext(ctx, &p.inFlight)
+	close(p.stopped)
+	close(p.jobs)
+	return err
+}
+
+// rateTracker calculates the achieved rate of executed page impressions per
+// second over a sliding measurement window.
+type rateTracker struct {
+	window       time.Duration
+	lastMeasured time.Time
+	lastCount    int64
+}
+
+func newRateTracker(window time.Duration, now time.Time) *rateTracker {
+	return &rateTracker{window: window, lastMeasured: now}
+}
+
+// Observe records the total count at the given time. If the measurement window
+// has passed it returns the achieved rate per second and true.
+func (t *rateTracker) Observe(now time.Time, count int64) (float64, bool) {
+	elapsed := now.Sub(t.lastMeasured)
+	if elapsed < t.window {
+		return 0, false
+	}
+
+	rate := float64(count-t.lastCount) / elapsed.Seconds()
+	t.lastMeasured = now
+	t.lastCount = count
+
+	return rate, true
+}
@@ -0,0 +1,81 @@
+package shop
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWorkerPoolSubmitRespectsMaxInFlight(t *testing.T) {
+	release := make(chan struct{})
+	pool := newWorkerPool(1, 2, func() { <-release })
+
+	for i := 0; i < 2; i++ {
+		if !pool.Submit() {
+			t.Fatalf("Submit() = false for page impression %d, want true", i)
+		}
+	}
+	if pool.Submit() {
+		t.Errorf("Submit() = true although max in-flight has been reached")
+	}
+
+	close(release)
+	if err := pool.Close(context.Background()); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if pool.Executed() != 2 {
+		t.Errorf("Executed() = %v, want 2", pool.Executed())
+	}
+}
+
+func TestWorkerPoolCloseDropsQueuedPageImpressionsAtDeadline(t *testing.T) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	pool := newWorkerPool(1, 10, func() {
+		started <- struct{}{}
+		<-release
+	})
+	for i := 0; i < 5; i++ {
+		pool.Submit()
+	}
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.Close(ctx); err == nil {
+		t.Fatalf("Close() error = nil, want deadline exceeded")
+	}
+	close(release)
+	pool.workerWg.Wait()
+
+	if pool.Executed() != 1 {
+		t.Errorf("Executed() = %v, want 1, queued page impressions must be dropped", pool.Executed())
+	}
+	if pool.inFlightCount.Load() != 0 {
+		t.Errorf("got %d page impressions in flight, want 0", pool.inFlightCount.Load())
+	}
+}
+
+func TestRateTracker(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newRateTracker(time.Second, start)
+
+	tests := []struct {
+		name     string
+		elapsed  time.Duration
+		count    int64
+		wantRate float64
+		wantOk   bool
+	}{
+		{name: "window not passed", elapsed: 500 * time.Millisecond, count: 10},
+		{name: "window passed", elapsed: 2 * time.Second, count: 40, wantRate: 20, wantOk: true},
+		{name: "next window not passed", elapsed: 2500 * time.Millisecond, count: 50},
+		{name: "next window passed", elapsed: 4 * time.Second, count: 60, wantRate: 10, wantOk: true},
+	}
+	for _, tt := range tests {
+		rate, ok := tracker.Observe(start.Add(tt.elapsed), tt.count)
+		if rate != tt.wantRate || ok != tt.wantOk {
+			t.Errorf("%v: Observe() = %v, %v, want %v, %v", tt.name, rate, ok, tt.wantRate, tt.wantOk)
+		}
+	}
+}
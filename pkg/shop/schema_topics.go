@@ -0,0 +1,208 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hamba/avro/v2"
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sr"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/cloudhut/owl-shop/pkg/sink"
+)
+
+// avroAPI encodes records with Avro schemas. Struct fields are matched by their
+// json tags, so that Avro and JSON records use the same field names.
+var avroAPI = avro.Config{TagKey: "json"}.Freeze()
+
+// protoAPI encodes protobuf messages. Map fields are sorted, so that records of
+// seeded runs are reproducible.
+var protoAPI = proto.MarshalOptions{Deterministic: true}
+
+// schemaTopics are the Protobuf, Avro and JSON schema registry encoded variants of
+// an entity's JSON topic. The subjects of the value schemas are derived from
+// the configured subject name strategy.
+type schemaTopics struct {
+	srClient schemaRegistryClient
+	subjects subjectNamer
+	// keys is nil if the records have no keys
+	keys *recordKeys
+	// messageType is the name of the encoded message, which is added as
+	// header to all records and qualified to the schemas' record names.
+	messageType string
+
+	protobufTopic  sink.Topic
+	protobufSchema string
+	avroTopic      sink.Topic
+	jsonTopic      sink.Topic
+
+	protobufSerde sr.Serde
+	avroSerde     sr.Serde
+	jsonSerde     sr.Serde
+}
+
+// newSchemaTopics creates the schema registry variants of an entity's topic.
+// The protobuf schema must define the given protobuf message as first message.
+// The Avro and JSON schemas are taken from the Avro and JSON topics.
+func newSchemaTopics(
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
+	keys *recordKeys,
+	messageType string,
+	protobufTopic sink.Topic,
+	protobufSchema string,
+	protobufMessage proto.Message,
+	avroTopic sink.Topic,
+	jsonTopic sink.Topic,
+) *schemaTopics {
+	protobufTopic.MessageType = protobufMessage.ProtoReflect().Type()
+
+	return &schemaTopics{
+		srClient:       srClient,
+		subjects:       subjects,
+		keys:           keys,
+		messageType:    messageType,
+		protobufTopic:  protobufTopic,
+		protobufSchema: protobufSchema,
+		avroTopic:      avroTopic,
+		jsonTopic:      jsonTopic,
+	}
+}
+
+// Initialize creates all topics and registers the value schemas. The given
+// value determines the Go type that is encoded with the Avro and JSON schemas.
+func (t *schemaTopics) Initialize(ctx context.Context, recordSink sink.Sink, value any) error {
+	if err := t.keys.CreateTopic(ctx, recordSink, t.protobufTopic); err != nil {
+		return fmt.Errorf("failed to create protobuf sr topic: %w", err)
+	}
+	protobufSchema, err := t.srClient.CreateSchema(ctx, t.subjects.subject(t.protobufTopic.Name, protobufRecordName(t.messageType)), sr.Schema{
+		Schema: t.protobufSchema,
+		Type:   sr.TypeProtobuf,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register protobuf schema: %w", err)
+	}
+	t.protobufSerde.Register(
+		protobufSchema.ID,
+		t.protobufTopic.MessageType.Zero().Interface(),
+		sr.EncodeFn(func(v any) ([]byte, error) {
+			return protoAPI.Marshal(v.(proto.Message))
+		}),
+		sr.Index(0),
+	)
+
+	if err := t.keys.CreateTopic(ctx, recordSink, t.avroTopic); err != nil {
+		return fmt.Errorf("failed to create avro sr topic: %w", err)
+	}
+	avroSchema, err := avro.Parse(t.avroTopic.Schema)
+	if err != nil {
+		return fmt.Errorf("failed to parse avro schema with avro lib: %w", err)
+	}
+	avroSubjectSchema, err := t.srClient.CreateSchema(ctx, t.subjects.subject(t.avroTopic.Name, avroRecordName(t.messageType)), sr.Schema{
+		Schema: t.avroTopic.Schema,
+		Type:   sr.TypeAvro,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register avro schema: %w", err)
+	}
+	t.avroSerde.Register(
+		avroSubjectSchema.ID,
+		value,
+		sr.EncodeFn(func(v any) ([]byte, error) {
+			return avroAPI.Marshal(avroSchema, v)
+		}),
+	)
+
+	if err := t.keys.CreateTopic(ctx, recordSink, t.jsonTopic); err != nil {
+		return fmt.Errorf("failed to create json sr topic: %w", err)
+	}
+	jsonSubjectSchema, err := t.srClient.CreateSchema(ctx, t.subjects.subject(t.jsonTopic.Name, jsonRecordName(t.messageType)), sr.Schema{
+		Schema: t.jsonTopic.Schema,
+		Type:   sr.TypeJSON,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to register json schema: %w", err)
+	}
+	t.jsonSerde.Register(jsonSubjectSchema.ID, value, sr.EncodeFn(json.Marshal))
+
+	return nil
+}
+
+// Records encodes the given values for all topics. The value is encoded
+// with the Avro and JSON schemas, the keys are derived from the given id.
+func (t *schemaTopics) Records(
+	id string,
+	timestamp time.Time,
+	headers []kgo.RecordHeader,
+	protobufValue proto.Message,
+	value any,
+) ([]*kgo.Record, error) {
+	protobufSerialized, err := t.protobufSerde.Encode(protobufValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode protobuf value: %w", err)
+	}
+	avroSerialized, err := t.avroSerde.Encode(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode avro value: %w", err)
+	}
+	jsonSerialized, err := t.jsonSerde.Encode(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode json value: %w", err)
+	}
+
+	records := make([]*kgo.Record, 0, 3)
+	for _, rec := range []struct {
+		topic      string
+		value      []byte
+		typeHeader string
+	}{
+		{t.protobufTopic.Name, protobufSerialized, "proto_message_type"},
+		{t.avroTopic.Name, avroSerialized, "avro_message_type"},
+		{t.jsonTopic.Name, jsonSerialized, "json_message_type"},
+	} {
+		key, err := t.keys.Encode(rec.topic, id)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, t.newRecord(rec.topic, key, rec.value, timestamp, headers, rec.typeHeader))
+	}
+
+	return records, nil
+}
+
+// Tombstones returns tombstones for the entity with the given id for all topics.
+func (t *schemaTopics) Tombstones(id string, timestamp time.Time) ([]*kgo.Record, error) {
+	records := make([]*kgo.Record, 0, 3)
+	for _, topic := range []string{t.protobufTopic.Name, t.avroTopic.Name, t.jsonTopic.Name} {
+		key, err := t.keys.Encode(topic, id)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, &kgo.Record{Key: key, Timestamp: timestamp, Topic: topic})
+	}
+
+	return records, nil
+}
+
+func (t *schemaTopics) newRecord(
+	topic string,
+	key, value []byte,
+	timestamp time.Time,
+	headers []kgo.RecordHeader,
+	typeHeader string,
+) *kgo.Record {
+	recordHeaders := make([]kgo.RecordHeader, 0, len(headers)+1)
+	recordHeaders = append(recordHeaders, headers...)
+	recordHeaders = append(recordHeaders, kgo.RecordHeader{Key: typeHeader, Value: []byte(t.messageType)})
+
+	return &kgo.Record{
+		Key:       key,
+		Value:     value,
+		Headers:   recordHeaders,
+		Timestamp: timestamp,
+		Topic:     topic,
+	}
+}
@@ -7,8 +7,26 @@ var (
 	CustomerV1Avro string
 	//go:embed customer_v2.avsc
 	CustomerV2Avro string
+	//go:embed customer_v3.avsc
+	CustomerV3Avro string
+	//go:embed customer_v4.avsc
+	CustomerV4Avro string
+	//go:embed customer_v5.avsc
+	CustomerV5Avro string
+	//go:embed customer_key.avsc
+	CustomerKeyAvro string
 	//go:embed address.avsc
 	AddressAvro string
+	//go:embed address_key.avsc
+	AddressKeyAvro string
 	//go:embed order.avsc
 	OrderAvro string
+	//go:embed order_key.avsc
+	OrderKeyAvro string
+	//go:embed shipment_key.avsc
+	ShipmentKeyAvro string
+	//go:embed payment_key.avsc
+	PaymentKeyAvro string
+	//go:embed frontend_event.avsc
+	FrontendEventAvro string
 )
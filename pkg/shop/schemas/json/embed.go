@@ -0,0 +1,14 @@
+package json
+
+import _ "embed"
+
+var (
+	//go:embed customer.json
+	CustomerJSON string
+	//go:embed address.json
+	AddressJSON string
+	//go:embed order.json
+	OrderJSON string
+	//go:embed frontend_event.json
+	FrontendEventJSON string
+)
@@ -0,0 +1,76 @@
+package shop
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/fake"
+	"github.com/cloudhut/owl-shop/pkg/kafka"
+	"github.com/cloudhut/owl-shop/pkg/sink"
+)
+
+// initializer is implemented by all services.
+type initializer interface {
+	Initialize(ctx context.Context) error
+}
+
+// serviceConstructor is the signature of the constructors of the services that
+// consume records.
+type serviceConstructor[S initializer] func(
+	cfg config.Shop,
+	logger *zap.Logger,
+	kafkaFactory *kafka.Factory,
+	sinkFactory sink.Factory,
+	generator *fake.Generator,
+	srClient schemaRegistryClient,
+	subjects subjectNamer,
+) (S, error)
+
+// newTestService creates and initializes a service that writes its records to
+// the returned stdout sink. The service doesn't consume from Kafka, records are
+// passed to its handlers instead.
+func newTestService[S initializer](t *testing.T, cfg config.Shop, generator *fake.Generator, newService serviceConstructor[S]) (S, *sink.Stdout) {
+	t.Helper()
+
+	stdout, err := sink.NewStdout(io.Discard, sink.StdoutFormatJSON)
+	if err != nil {
+		t.Fatalf("failed to create sink: %v", err)
+	}
+	svc, err := newService(cfg, zap.NewNop(), nil, stdout, generator, nil, newSubjectNamer(config.SchemaRegistry{}))
+	if err != nil {
+		t.Fatalf("failed to create service: %v", err)
+	}
+	if err := svc.Initialize(context.Background()); err != nil {
+		t.Fatalf("Initialize() error = %v", err)
+	}
+
+	return svc, stdout
+}
+
+// collectRecords returns the records that are produced to the topic after
+// calling it.
+func collectRecords(stdout *sink.Stdout, topic string) *[]*kgo.Record {
+	var produced []*kgo.Record
+	stdout.Subscribe(topic, func(rec *kgo.Record) {
+		produced = append(produced, rec)
+	})
+	return &produced
+}
+
+// jsonRecord returns a record with the JSON serialized value, as consumed by the
+// service handlers.
+func jsonRecord(t *testing.T, value any) *kgo.Record {
+	t.Helper()
+
+	serialized, err := json.Marshal(value)
+	if err != nil {
+		t.Fatalf("failed to serialize record value: %v", err)
+	}
+	return &kgo.Record{Value: serialized}
+}
@@ -2,150 +2,646 @@ package shop
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mroth/weightedrand"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/twmb/franz-go/pkg/kgo"
 	"go.uber.org/zap"
 
 	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/fake"
 	"github.com/cloudhut/owl-shop/pkg/kafka"
+	"github.com/cloudhut/owl-shop/pkg/sink"
 	"github.com/cloudhut/owl-shop/pkg/sr"
+	"github.com/cloudhut/owl-shop/pkg/traffic"
 )
 
+// errBufferEmpty is returned by actions that require a previously created
+// entity (e.g. a customer), if there is none in the service's buffer yet.
+var errBufferEmpty = errors.New("buffer is empty")
+
 type Shop struct {
 	cfg    config.Config
 	logger *zap.Logger
 
-	chooser *weightedrand.Chooser
+	generator *fake.Generator
+
+	// actions contains all actions that can be picked by the chooser, keyed by their name.
+	actions map[string]func() error
+
+	// budget counts all executed actions and enforces the configured max events.
+	budget *eventBudget
+
+	// mu guards the simulation settings that can be changed at runtime via the control API.
+	mu                  sync.RWMutex
+	requestRate         int
+	requestRateInterval time.Duration
+	eventMix            config.ShopEventMix
+	chooser             *weightedrand.Chooser
+	paused              atomic.Bool
+
+	// metaClient is nil if records are not written to Kafka.
+	metaClient *kgo.Client
+	// sharedSink is the sink that is shared by all services if records are not
+	// written to Kafka. It must be closed after all services have been closed.
+	sharedSink sink.Sink
 
 	// Services
 	customerSvc *CustomerService
+	addressSvc  *AddressService
+	frontendSvc *FrontendService
+	orderSvc    *OrderService
+	deliverySvc *DeliveryService
+	paymentSvc  *PaymentService
+	// metaSvc is nil if records are not written to Kafka.
+	metaSvc *MetaService
 }
 
 func New(cfg config.Config, logger *zap.Logger) (*Shop, error) {
-	kafkaFactory := kafka.NewFactory(cfg.Kafka, logger.Named("kafka_client"))
 	schemaFactory := sr.NewFactory(cfg.SchemaRegistry, logger.Named("schema_registry"))
 
-	metaKafkaCl, err := kafkaFactory.NewKafkaClient(cfg.Shop.GlobalPrefix + "meta-service")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create meta kafka client")
-	}
-
 	// srClient may be nil if schema registry hasn't been configured
-	srClient, err := schemaFactory.NewSchemaRegistryClient()
+	var srClient schemaRegistryClient
+	franzSrClient, err := schemaFactory.NewSchemaRegistryClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create schema registry client")
 	}
+	if franzSrClient != nil {
+		srClient, err = sr.NewCompatibilityClient(franzSrClient, cfg.SchemaRegistry.Compatibility)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create schema registry client: %w", err)
+		}
+	}
+
+	// kafkaFactory, metaKafkaCl and localSink are nil depending on the configured sink type
+	var (
+		kafkaFactory *kafka.Factory
+		metaKafkaCl  *kgo.Client
+		localSink    sink.Local
+		sinkFactory  sink.Factory
+	)
+	switch cfg.Sink.Type {
+	case config.SinkTypeFile:
+		localSink, err = sink.NewFile(cfg.Sink.File.Directory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create file sink: %w", err)
+		}
+
+		// Without a schema registry the schema registry encoded topics would be
+		// missing from the generated dataset, hence we register the schemas in memory.
+		if srClient == nil {
+			srClient = sr.NewInMemoryRegistry()
+		}
+	case config.SinkTypeStdout:
+		localSink, err = sink.NewStdout(os.Stdout, cfg.Sink.Stdout.Format)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stdout sink: %w", err)
+		}
+
+		// Schemas must not be created in dry-run mode, but are still
+		// needed to encode the schema registry encoded records.
+		srClient = sr.NewInMemoryRegistry()
+	default:
+		kafkaFactory = kafka.NewFactory(cfg.Kafka, logger.Named("kafka_client"))
+		sinkFactory = sink.NewKafkaFactory(kafkaFactory, logger.Named("topic_reconciler"), cfg.Shop.ReconcileMode)
+
+		metaKafkaCl, err = kafkaFactory.NewKafkaClient(cfg.Shop.GlobalPrefix + "meta-service")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create meta kafka client")
+		}
+	}
+	if localSink != nil {
+		sinkFactory = localSink
+
+		// Local sinks decode all records of a topic with the schema the topic
+		// has been created with, hence they can't follow the schema evolution.
+		if len(cfg.Shop.SchemaEvolution.Steps) > 0 {
+			logger.Warn("schema evolution is only supported by the kafka sink, ignoring configured steps",
+				zap.String("sink_type", cfg.Sink.Type))
+			cfg.Shop.SchemaEvolution.Steps = nil
+		}
+	}
 
-	customerSvc, err := NewCustomerService(cfg.Shop, logger, kafkaFactory)
+	generator := fake.NewGenerator(cfg.Shop.Seed)
+	subjects := newSubjectNamer(cfg.SchemaRegistry)
+
+	customerSvc, err := NewCustomerService(cfg.Shop, logger, sinkFactory, generator, srClient, subjects)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create customer service: %w", err)
 	}
 
-	addressSvc, err := NewAddressService(cfg.Shop, logger.Named("address_svc"), kafkaFactory)
+	addressSvc, err := NewAddressService(cfg.Shop, logger.Named("address_svc"), kafkaFactory, sinkFactory, generator, srClient, subjects)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create address service: %w", err)
 	}
 
-	frontendSvc, err := NewFrontendService(cfg.Shop, logger.Named("frontend_svc"), kafkaFactory)
+	frontendSvc, err := NewFrontendService(cfg.Shop, logger.Named("frontend_svc"), sinkFactory, generator, srClient, subjects)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create frontend service: %w", err)
 	}
 
-	orderSvc, err := NewOrderService(cfg.Shop, logger.Named("order_svc"), kafkaFactory, srClient)
+	orderSvc, err := NewOrderService(cfg.Shop, logger.Named("order_svc"), kafkaFactory, sinkFactory, generator, srClient, subjects)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create order service: %w", err)
 	}
 
-	metaSvc, err := NewMetaService(cfg.Shop, logger.Named("meta-svc"), metaKafkaCl)
+	deliverySvc, err := NewDeliveryService(cfg.Shop, logger.Named("delivery_svc"), kafkaFactory, sinkFactory, generator, srClient, subjects)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create meta service: %w", err)
+		return nil, fmt.Errorf("failed to create delivery service: %w", err)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
-	defer cancel()
-
-	err = customerSvc.Initialize(ctx)
+	paymentSvc, err := NewPaymentService(cfg.Shop, logger.Named("payment_svc"), kafkaFactory, sinkFactory, generator, srClient, subjects)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize customer service: %w", err)
+		return nil, fmt.Errorf("failed to create payment service: %w", err)
 	}
 
-	err = addressSvc.Initialize(ctx)
+	if localSink != nil {
+		// There are no consumers without Kafka, so customers, orders and shipments
+		// are passed directly to the services that depend on them.
+		customersTopic := cfg.Shop.Topic(config.ShopTopicCustomers).Name
+		localSink.Subscribe(customersTopic, addressSvc.HandleCustomerRecord)
+		localSink.Subscribe(customersTopic, orderSvc.HandleCustomerRecord)
+		ordersTopic := cfg.Shop.Topic(config.ShopTopicOrders).Name
+		localSink.Subscribe(ordersTopic, deliverySvc.HandleOrderRecord)
+		localSink.Subscribe(ordersTopic, paymentSvc.HandleOrderRecord)
+		localSink.Subscribe(cfg.Shop.Topic(config.ShopTopicShipments).Name, orderSvc.HandleShipmentRecord)
+	}
+
+	// The meta service only has a purpose if we produce to a Kafka cluster
+	var metaSvc *MetaService
+	if metaKafkaCl != nil {
+		metaSvc, err = NewMetaService(cfg.Shop, logger.Named("meta-svc"), metaKafkaCl)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create meta service: %w", err)
+		}
+	}
+
+	// Random chooser
+	actions := map[string]func() error{
+		config.ShopActionFrontendEvent:  frontendSvc.CreateFrontendEvent,
+		config.ShopActionCreateCustomer: customerSvc.CreateCustomer,
+		config.ShopActionModifyCustomer: customerSvc.ModifyCustomer,
+		config.ShopActionDeleteCustomer: customerSvc.DeleteCustomer,
+		config.ShopActionCreateAddress:  addressSvc.CreateAddress,
+		config.ShopActionCreateOrder:    orderSvc.CreateOrder,
+		config.ShopActionUpdateOrder:    orderSvc.UpdateOrder,
+		config.ShopActionScanShipment:   deliverySvc.ScanShipment,
+	}
+	wr, err := newActionChooser(cfg.Shop.EventMix, actions)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize address service: %w", err)
+		return nil, fmt.Errorf("failed to create random chooser: %w", err)
 	}
 
-	err = frontendSvc.Initialize(ctx)
+	return &Shop{
+		cfg:    cfg,
+		logger: logger,
+
+		generator: generator,
+		actions:   actions,
+		budget:    newEventBudget(cfg.Shop.MaxEvents.Total, cfg.Shop.MaxEvents.Actions),
+
+		requestRate:         cfg.Shop.RequestRate,
+		requestRateInterval: cfg.Shop.RequestRateInterval,
+		eventMix:            cfg.Shop.EventMix,
+		chooser:             wr,
+
+		metaClient: metaKafkaCl,
+		sharedSink: localSink,
+
+		customerSvc: customerSvc,
+		addressSvc:  addressSvc,
+		frontendSvc: frontendSvc,
+		orderSvc:    orderSvc,
+		deliverySvc: deliverySvc,
+		paymentSvc:  paymentSvc,
+		metaSvc:     metaSvc,
+	}, nil
+}
+
+// Initialize creates all resources that are required by the shop's services, such
+// as topics, schemas and ACLs. It must be called before starting the shop.
+func (s *Shop) Initialize(ctx context.Context) error {
+	// The order service registers the evolution of the referenced customer schemas.
+	// Depending on the subject name strategy, the customer service registers its
+	// schemas under the same subjects, so that it must be initialized afterwards.
+	err := s.orderSvc.Initialize(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize frontend service: %w", err)
+		return fmt.Errorf("failed to initialize order service: %w", err)
 	}
 
-	err = orderSvc.Initialize(ctx)
+	err = s.customerSvc.Initialize(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize order service: %w", err)
+		return fmt.Errorf("failed to initialize customer service: %w", err)
 	}
 
-	err = metaSvc.Initialize(ctx)
+	err = s.addressSvc.Initialize(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to initialize meta service: %w", err)
+		return fmt.Errorf("failed to initialize address service: %w", err)
 	}
 
-	go addressSvc.Start()
-	go orderSvc.Start()
+	err = s.frontendSvc.Initialize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize frontend service: %w", err)
+	}
 
-	// Random chooser
-	wr, err := weightedrand.NewChooser(
-		weightedrand.Choice{Item: frontendSvc.CreateFrontendEvent, Weight: 1000},
-		weightedrand.Choice{Item: customerSvc.CreateCustomer, Weight: 50},
-		weightedrand.Choice{Item: addressSvc.CreateAddress, Weight: 30},
-		weightedrand.Choice{Item: customerSvc.DeleteCustomer, Weight: 8},
-		weightedrand.Choice{Item: customerSvc.ModifyCustomer, Weight: 6},
-		weightedrand.Choice{Item: orderSvc.CreateOrder, Weight: 5},
-	)
+	err = s.deliverySvc.Initialize(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create random chooser: %w", err)
+		return fmt.Errorf("failed to initialize delivery service: %w", err)
 	}
 
-	return &Shop{
-		cfg:    cfg,
-		logger: logger,
+	err = s.paymentSvc.Initialize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize payment service: %w", err)
+	}
 
-		chooser: wr,
+	if s.metaSvc != nil {
+		err = s.metaSvc.Initialize(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to initialize meta service: %w", err)
+		}
+	}
 
-		customerSvc: customerSvc,
-	}, nil
+	return nil
 }
 
 // Start starts all shop components and triggers events (e.g. customer registration) in accordance with the
-// config for traffic simulation.
-func (s *Shop) Start() error {
-	http.Handle("/metrics", promhttp.Handler())
+// config for traffic simulation. It blocks until the given context is cancelled or the configured run bounds
+// have been reached and then gracefully shuts down all components within the configured drain timeout.
+func (s *Shop) Start(ctx context.Context) error {
+	startedAt := time.Now()
+	if s.cfg.Shop.RunFor > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.Shop.RunFor)
+		defer cancel()
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	s.registerControlAPI(mux)
+	httpServer := &http.Server{Addr: ":8080", Handler: mux}
 	go func() {
-		err := http.ListenAndServe(":8080", nil)
+		err := httpServer.ListenAndServe()
 		s.logger.Info("prometheus http handler quit", zap.Error(err))
 	}()
 
+	// Consumers are stopped independently of the simulation loop, because
+	// they must only be stopped once all in-flight page impressions are done.
+	consumerCtx, cancelConsumers := context.WithCancel(context.Background())
+	defer cancelConsumers()
+	consumersWg := sync.WaitGroup{}
+	consumersWg.Add(4)
+	go func() {
+		defer consumersWg.Done()
+		s.addressSvc.Start(consumerCtx)
+	}()
+	go func() {
+		defer consumersWg.Done()
+		s.orderSvc.Start(consumerCtx)
+	}()
+	go func() {
+		defer consumersWg.Done()
+		s.deliverySvc.Start(consumerCtx)
+	}()
+	go func() {
+		defer consumersWg.Done()
+		s.paymentSvc.Start(consumerCtx)
+	}()
+
+	pool := newWorkerPool(s.cfg.Shop.Workers, s.cfg.Shop.MaxInFlight, s.SimulatePageImpression)
+
+	s.schedulePageImpressions(ctx, pool)
+
+	s.logger.Info("stopping traffic simulation, draining shop",
+		zap.Duration("drain_timeout", s.cfg.Shop.DrainTimeout))
+	drainCtx, cancel := context.WithTimeout(context.Background(), s.cfg.Shop.DrainTimeout)
+	defer cancel()
+
+	err := s.shutdown(drainCtx, httpServer, pool, cancelConsumers, &consumersWg)
+	if err != nil {
+		return fmt.Errorf("failed to gracefully shutdown shop: %w", err)
+	}
+	s.logger.Info("successfully shutdown shop")
+	s.logSummary(startedAt)
+
+	return nil
+}
+
+// logSummary logs the number of executed actions since the given start time.
+func (s *Shop) logSummary(startedAt time.Time) {
+	total, executed := s.budget.Executed()
+
+	fields := []zap.Field{
+		zap.Duration("duration", time.Since(startedAt)),
+		zap.Uint64("total_events", total),
+	}
+	for _, action := range config.ShopActions() {
+		fields = append(fields, zap.Uint64(action, executed[action]))
+	}
+	s.logger.Info("run summary", fields...)
+}
+
+// schedulePageImpressions submits page impressions to the worker pool at the configured
+// request rate, modulated by the traffic profile, until the given context is cancelled.
+// Page impressions are evenly spread across the request rate interval using a token bucket.
+func (s *Shop) schedulePageImpressions(ctx context.Context, pool *workerPool) {
+	now := time.Now()
+	profile := traffic.NewProfile(s.cfg.Shop.Traffic, now, s.cfg.Shop.Seed)
+	bucket := traffic.NewTokenBucket(time.Second, now)
+	achievedRate := newRateTracker(5*time.Second, now)
+	ticker := time.NewTicker(schedulerTickInterval)
+	defer ticker.Stop()
+
 	for {
-		for i := 0; i < s.cfg.Shop.RequestRate; i++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.budget.Exhausted():
+			s.logger.Info("reached configured max events")
+			return
+		case now = <-ticker.C:
+		}
+
+		if s.paused.Load() {
+			// Advance the token bucket without collecting tokens, so that we
+			// don't burst once the simulation is resumed.
+			bucket.Take(now, 0)
+			pageImpressionsTargetRate.Set(0)
+			continue
+		}
+
+		requestRate, interval := s.Rate()
+		factor := profile.Factor(now)
+		targetRate := float64(requestRate) / interval.Seconds() * factor
+		trafficFactor.Set(factor)
+		pageImpressionsTargetRate.Set(targetRate)
+
+		impressions, discarded := bucket.Take(now, targetRate)
+		if discarded > 0 {
+			pageImpressionsDroppedTotal.With(map[string]string{"reason": DropReasonSchedulerLag}).Add(float64(discarded))
+		}
+		for i := 0; i < impressions; i++ {
+			if !pool.Submit() {
+				pageImpressionsDroppedTotal.With(map[string]string{"reason": DropReasonMaxInFlight}).Inc()
+				continue
+			}
 			pageImpressionsSimulated.Inc()
-			s.SimulatePageImpression()
 		}
-		time.Sleep(s.cfg.Shop.RequestRateInterval)
+
+		if rate, ok := achievedRate.Observe(now, pool.Executed()); ok {
+			pageImpressionsAchievedRate.Set(rate)
+		}
 	}
 }
 
+// shutdown waits for all in-flight page impressions, flushes all producers, commits
+// the consumed offsets and closes all Kafka clients. Errors are collected so that
+// we try to close as many components as possible, even if one of them fails.
+func (s *Shop) shutdown(
+	ctx context.Context,
+	httpServer *http.Server,
+	pool *workerPool,
+	cancelConsumers context.CancelFunc,
+	consumersWg *sync.WaitGroup,
+) error {
+	var errs []error
+
+	// Shutdown the http server first, so that the control API can not trigger
+	// any further actions while we drain the shop.
+	if err := httpServer.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to shutdown http server: %w", err))
+	}
+
+	if err := pool.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to wait for in-flight page impressions: %w", err))
+	}
+
+	cancelConsumers()
+	if err := waitWithContext(ctx, consumersWg); err != nil {
+		errs = append(errs, fmt.Errorf("failed to wait for consumers to stop: %w", err))
+	}
+
+	if err := s.Close(ctx); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Close flushes all producers, commits the consumed offsets and closes all clients.
+// If the shop has been started, Start closes the shop on its own.
+func (s *Shop) Close(ctx context.Context) error {
+	var errs []error
+
+	if err := s.customerSvc.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close customer service: %w", err))
+	}
+	if err := s.addressSvc.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close address service: %w", err))
+	}
+	if err := s.frontendSvc.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close frontend service: %w", err))
+	}
+	if err := s.orderSvc.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close order service: %w", err))
+	}
+	if err := s.deliverySvc.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close delivery service: %w", err))
+	}
+	if err := s.paymentSvc.Close(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to close payment service: %w", err))
+	}
+	if s.metaClient != nil {
+		s.metaClient.Close()
+	}
+	if s.sharedSink != nil {
+		s.sharedSink.Close()
+	}
+
+	return errors.Join(errs...)
+}
+
 // SimulatePageImpression simulates a user visiting a page in our imaginary owl shop. This page impression can be a
 // user registration, oder, viewing articles or doing anything else a common user would do in a shop.
 func (s *Shop) SimulatePageImpression() {
+	s.mu.RLock()
+	chooser := s.chooser
+	s.mu.RUnlock()
 
-	go func() {
-		fn, isOk := s.chooser.Pick().(func())
-		if !isOk {
-			s.logger.Fatal("randomly picked method is not a func")
+	action, isOk := s.generator.Pick(chooser).(string)
+	if !isOk {
+		s.logger.Fatal("randomly picked action is not a string")
+	}
+	s.executeAction(action)
+}
+
+// executeAction executes the action with the given name, unless the configured max
+// events would be exceeded. It returns true if the action has been executed successfully.
+func (s *Shop) executeAction(action string) bool {
+	if !s.budget.Reserve(action) {
+		return false
+	}
+
+	err := s.actions[action]()
+	if s.budget.Complete(action, err == nil) {
+		s.logger.Info("action reached its max events, disabling it", zap.String("action", action))
+		s.disableAction(action)
+	}
+
+	return err == nil
+}
+
+// disableAction sets the weight of the given action to 0, so that it is no longer picked.
+func (s *Shop) disableAction(action string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	eventMix := make(config.ShopEventMix, len(s.eventMix))
+	for name, weight := range s.eventMix {
+		eventMix[name] = weight
+	}
+	eventMix[action] = 0
+
+	chooser, err := newActionChooser(eventMix, s.actions)
+	if err != nil {
+		// All actions are disabled, which means that the event budget is exhausted as well
+		return
+	}
+	s.eventMix = eventMix
+	s.chooser = chooser
+}
+
+// Rate returns the current request rate and the interval it refers to.
+func (s *Shop) Rate() (int, time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.requestRate, s.requestRateInterval
+}
+
+// SetRate changes the request rate of the running traffic simulation.
+func (s *Shop) SetRate(requestRate int, interval time.Duration) error {
+	if requestRate <= 0 {
+		return fmt.Errorf("request rate must be a positive integer")
+	}
+	if interval <= 0 {
+		return fmt.Errorf("interval must be a positive duration")
+	}
+
+	s.mu.Lock()
+	s.requestRate = requestRate
+	s.requestRateInterval = interval
+	s.mu.Unlock()
+
+	s.logger.Info("changed request rate", zap.Int("request_rate", requestRate), zap.Duration("interval", interval))
+
+	return nil
+}
+
+// EventMix returns a copy of the current event mix.
+func (s *Shop) EventMix() config.ShopEventMix {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	eventMix := make(config.ShopEventMix, len(s.eventMix))
+	for action, weight := range s.eventMix {
+		eventMix[action] = weight
+	}
+
+	return eventMix
+}
+
+// SetEventMix replaces the event mix of the running traffic simulation.
+func (s *Shop) SetEventMix(eventMix config.ShopEventMix) error {
+	if err := eventMix.Validate(); err != nil {
+		return err
+	}
+
+	chooser, err := newActionChooser(eventMix, s.actions)
+	if err != nil {
+		return fmt.Errorf("failed to create random chooser: %w", err)
+	}
+
+	s.mu.Lock()
+	s.eventMix = eventMix
+	s.chooser = chooser
+	s.mu.Unlock()
+
+	s.logger.Info("changed event mix", zap.Any("event_mix", eventMix))
+
+	return nil
+}
+
+// Pause stops simulating page impressions until Resume is called.
+func (s *Shop) Pause() {
+	if s.paused.CompareAndSwap(false, true) {
+		s.logger.Info("paused traffic simulation")
+	}
+}
+
+// Resume continues simulating page impressions after Pause has been called.
+func (s *Shop) Resume() {
+	if s.paused.CompareAndSwap(true, false) {
+		s.logger.Info("resumed traffic simulation")
+	}
+}
+
+// TriggerAction synchronously executes the action with the given name count times,
+// regardless of the event mix or whether the traffic simulation is paused. It returns
+// the number of successful executions.
+func (s *Shop) TriggerAction(action string, count int) (int, error) {
+	if _, exists := s.actions[action]; !exists {
+		return 0, fmt.Errorf("unknown action '%v'", action)
+	}
+
+	executed := 0
+	for i := 0; i < count; i++ {
+		if s.executeAction(action) {
+			executed++
+		}
+	}
+
+	return executed, nil
+}
+
+// newActionChooser creates a weighted random chooser that picks one of the given
+// actions according to the configured event mix. Actions with a weight of 0 are
+// never picked.
+func newActionChooser(eventMix config.ShopEventMix, actions map[string]func() error) (*weightedrand.Chooser, error) {
+	choices := make([]weightedrand.Choice, 0, len(actions))
+	for _, name := range config.ShopActions() {
+		weight := eventMix[name]
+		if weight == 0 {
+			continue
 		}
-		fn()
+		if _, exists := actions[name]; !exists {
+			return nil, fmt.Errorf("no action registered for name '%v'", name)
+		}
+		choices = append(choices, weightedrand.Choice{Item: name, Weight: weight})
+	}
+
+	return weightedrand.NewChooser(choices...)
+}
+
+// waitWithContext waits until the WaitGroup's counter is zero or the context is done,
+// whichever happens first.
+func waitWithContext(ctx context.Context, wg *sync.WaitGroup) error {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
 	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 }
@@ -0,0 +1,106 @@
+package shop
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hamba/avro/v2/ocf"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+func TestShopWithSeedIsReproducible(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping simulation in short mode")
+	}
+
+	run := func() map[string][]byte {
+		var cfg config.Config
+		cfg.SetDefaults()
+		cfg.Sink.Type = config.SinkTypeFile
+		cfg.Sink.File.Directory = t.TempDir()
+		cfg.Shop.Seed = 42
+		cfg.Shop.Workers = 1
+		cfg.Shop.RequestRate = 1000
+		cfg.Shop.MaxEvents.Total = 300
+		// The shop is stopped by the max events, run for is a safety net
+		cfg.Shop.RunFor = 30 * time.Second
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("failed to validate config: %v", err)
+		}
+
+		shopSvc, err := New(cfg, zap.NewNop())
+		if err != nil {
+			t.Fatalf("New() error = %v", err)
+		}
+		if err := shopSvc.Initialize(context.Background()); err != nil {
+			t.Fatalf("Initialize() error = %v", err)
+		}
+		if err := shopSvc.Start(context.Background()); err != nil {
+			t.Fatalf("Start() error = %v", err)
+		}
+
+		files, err := os.ReadDir(cfg.Sink.File.Directory)
+		if err != nil {
+			t.Fatalf("failed to list files: %v", err)
+		}
+		contents := make(map[string][]byte, len(files))
+		for _, file := range files {
+			content, err := os.ReadFile(filepath.Join(cfg.Sink.File.Directory, file.Name()))
+			if err != nil {
+				t.Fatalf("failed to read file: %v", err)
+			}
+			contents[file.Name()] = content
+		}
+		return contents
+	}
+
+	first := run()
+	second := run()
+	if len(first) == 0 {
+		t.Fatalf("no files have been written")
+	}
+	if len(first) != len(second) {
+		t.Fatalf("got %d files in the first run and %d files in the second run", len(first), len(second))
+	}
+	for name, content := range first {
+		if filepath.Ext(name) == ".avro" {
+			// Avro maps are encoded in random order, hence their records are compared
+			if !reflect.DeepEqual(decodeAvroFile(t, content), decodeAvroFile(t, second[name])) {
+				t.Errorf("records of file %v differ between runs with the same seed", name)
+			}
+			continue
+		}
+		if !bytes.Equal(content, second[name]) {
+			t.Errorf("file %v differs between runs with the same seed", name)
+		}
+	}
+}
+
+func decodeAvroFile(t *testing.T, content []byte) []any {
+	t.Helper()
+
+	decoder, err := ocf.NewDecoder(bytes.NewReader(content))
+	if err != nil {
+		t.Fatalf("failed to create avro decoder: %v", err)
+	}
+	var records []any
+	for decoder.HasNext() {
+		var record any
+		if err := decoder.Decode(&record); err != nil {
+			t.Fatalf("failed to decode avro record: %v", err)
+		}
+		records = append(records, record)
+	}
+	if err := decoder.Error(); err != nil {
+		t.Fatalf("failed to decode avro file: %v", err)
+	}
+
+	return records
+}
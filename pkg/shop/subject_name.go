@@ -0,0 +1,130 @@
+package shop
+
+import (
+	"strings"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+const (
+	// protobufPackage is the package of all shop protobuf messages.
+	protobufPackage = "shop.v1"
+	// avroNamespace is the namespace of all shop Avro records.
+	avroNamespace = "com.shop.v1.avro"
+)
+
+// Message types of all schemas. Schemas of the referenced message types are
+// registered before the schemas that reference them.
+var (
+	referencingMessageTypes = []string{"Order", "FrontendEvent"}
+	referencedMessageTypes  = []string{"Customer", "Address"}
+	keyMessageTypes         = []string{"CustomerKey", "AddressKey", "OrderKey", "ShipmentKey", "PaymentKey"}
+)
+
+// protobufRecordName returns the fully qualified name of a protobuf message.
+func protobufRecordName(messageType string) string {
+	return protobufPackage + "." + messageType
+}
+
+// avroRecordName returns the fully qualified name of an Avro record.
+func avroRecordName(messageType string) string {
+	return avroNamespace + "." + messageType
+}
+
+// jsonRecordName returns the record name of a JSON schema, which is its title.
+func jsonRecordName(messageType string) string {
+	return messageType
+}
+
+// recordNames returns the record names of a message type for all formats.
+func recordNames(messageType string) []string {
+	return []string{protobufRecordName(messageType), avroRecordName(messageType), jsonRecordName(messageType)}
+}
+
+// subjectNamer derives the schema registry subjects according to the configured
+// subject name strategy.
+type subjectNamer struct {
+	strategy string
+}
+
+func newSubjectNamer(cfg config.SchemaRegistry) subjectNamer {
+	return subjectNamer{strategy: cfg.SubjectNameStrategy}
+}
+
+// subject returns the subject of a topic's value schema with the given record name.
+func (n subjectNamer) subject(topic, recordName string) string {
+	switch n.strategy {
+	case config.SubjectNameStrategyRecordName:
+		return recordName
+	case config.SubjectNameStrategyTopicRecordName:
+		return topic + "-" + recordName
+	}
+
+	return topic + "-value"
+}
+
+// keySubject returns the subject of a topic's key schema with the given record name.
+func (n subjectNamer) keySubject(topic, recordName string) string {
+	if n.strategy == config.SubjectNameStrategyTopicName {
+		return topic + "-key"
+	}
+
+	return n.subject(topic, recordName)
+}
+
+// referenceSubject returns the subject of a schema that is referenced by the value
+// schema of the given topic. With the topic name strategy a topic has only one value
+// subject, hence referenced schemas are registered under the given shared subject.
+func (n subjectNamer) referenceSubject(topic, recordName, sharedSubject string) string {
+	if n.strategy == config.SubjectNameStrategyTopicName {
+		return sharedSubject
+	}
+
+	return n.subject(topic, recordName)
+}
+
+// sharedSubjects returns the subjects that are not scoped to the global prefix.
+// Referenced subjects are returned last, so that they can be deleted after the
+// subjects that reference them.
+func (n subjectNamer) sharedSubjects() []string {
+	switch n.strategy {
+	case config.SubjectNameStrategyRecordName:
+		var subjects []string
+		for _, messageType := range referencingMessageTypes {
+			subjects = append(subjects, recordNames(messageType)...)
+		}
+		// Keys are never encoded as JSON Schema
+		for _, messageType := range keyMessageTypes {
+			subjects = append(subjects, protobufRecordName(messageType), avroRecordName(messageType))
+		}
+		for _, messageType := range referencedMessageTypes {
+			subjects = append(subjects, recordNames(messageType)...)
+		}
+		return subjects
+	case config.SubjectNameStrategyTopicRecordName:
+		return nil
+	}
+
+	return []string{
+		subjectCustomerProto,
+		subjectAddressProto,
+		subjectCustomerAvro,
+		subjectAddressAvro,
+		subjectCustomerJSON,
+		subjectAddressJSON,
+	}
+}
+
+// isReferencedSubject returns true if the subject's schema may be referenced by
+// the schemas of other subjects.
+func isReferencedSubject(subject string) bool {
+	for _, messageType := range referencedMessageTypes {
+		for _, recordName := range recordNames(messageType) {
+			if subject == recordName || strings.HasSuffix(subject, "-"+recordName) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,94 @@
+package shop
+
+import (
+	"testing"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+func TestSubjectNamer(t *testing.T) {
+	const topic = "owlshop-orders-avro-sr"
+	recordName := avroRecordName("Order")
+	keyRecordName := avroRecordName("OrderKey")
+	customerRecordName := avroRecordName("Customer")
+
+	tests := []struct {
+		strategy         string
+		wantSubject      string
+		wantKeySubject   string
+		wantReference    string
+		wantSharedLength int
+		// checkSharedOrder is only set if referencing subjects are shared as well
+		checkSharedOrder bool
+	}{
+		{
+			strategy:         config.SubjectNameStrategyTopicName,
+			wantSubject:      topic + "-value",
+			wantKeySubject:   topic + "-key",
+			wantReference:    subjectCustomerAvro,
+			wantSharedLength: 6,
+		},
+		{
+			strategy:         config.SubjectNameStrategyRecordName,
+			wantSubject:      recordName,
+			wantKeySubject:   keyRecordName,
+			wantReference:    customerRecordName,
+			wantSharedLength: 3*len(referencingMessageTypes) + 2*len(keyMessageTypes) + 3*len(referencedMessageTypes),
+			checkSharedOrder: true,
+		},
+		{
+			strategy:       config.SubjectNameStrategyTopicRecordName,
+			wantSubject:    topic + "-" + recordName,
+			wantKeySubject: topic + "-" + keyRecordName,
+			wantReference:  topic + "-" + customerRecordName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.strategy, func(t *testing.T) {
+			namer := newSubjectNamer(config.SchemaRegistry{SubjectNameStrategy: tt.strategy})
+			if got := namer.subject(topic, recordName); got != tt.wantSubject {
+				t.Errorf("subject() = %v, want %v", got, tt.wantSubject)
+			}
+			if got := namer.keySubject(topic, keyRecordName); got != tt.wantKeySubject {
+				t.Errorf("keySubject() = %v, want %v", got, tt.wantKeySubject)
+			}
+			if got := namer.referenceSubject(topic, customerRecordName, subjectCustomerAvro); got != tt.wantReference {
+				t.Errorf("referenceSubject() = %v, want %v", got, tt.wantReference)
+			}
+
+			shared := namer.sharedSubjects()
+			if len(shared) != tt.wantSharedLength {
+				t.Fatalf("got %d shared subjects, want %d", len(shared), tt.wantSharedLength)
+			}
+			if !tt.checkSharedOrder {
+				return
+			}
+			// Referenced subjects must be deleted after all other subjects
+			seenReferenced := false
+			for _, subject := range shared {
+				if isReferencedSubject(subject) {
+					seenReferenced = true
+				} else if seenReferenced {
+					t.Errorf("subject %v is returned after a referenced subject", subject)
+				}
+			}
+		})
+	}
+}
+
+func TestIsReferencedSubject(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    bool
+	}{
+		{subject: subjectCustomerAvro, want: true},
+		{subject: "owlshop-orders-protobuf-sr-shop.v1.Address", want: true},
+		{subject: "owlshop-orders-avro-sr-com.shop.v1.avro.Order"},
+		{subject: "owlshop-customers-value"},
+	}
+	for _, tt := range tests {
+		if got := isReferencedSubject(tt.subject); got != tt.want {
+			t.Errorf("isReferencedSubject(%v) = %v, want %v", tt.subject, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,363 @@
+package shop
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/twmb/franz-go/pkg/kadm"
+	"github.com/twmb/franz-go/pkg/kgo"
+	franzsr "github.com/twmb/franz-go/pkg/sr"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/kafka"
+	"github.com/cloudhut/owl-shop/pkg/sr"
+)
+
+// TeardownPlan lists all resources of a shop that are deleted by a teardown.
+// Resources are matched by their exact names rather than by the global prefix,
+// so that shops whose prefix starts with this shop's prefix are left untouched.
+type TeardownPlan struct {
+	GlobalPrefix   string
+	Topics         []string
+	ConsumerGroups []string
+	ACLs           []kadm.DescribedACL
+	// Subjects are ordered so that referencing subjects are deleted before
+	// the subjects they reference.
+	Subjects []string
+}
+
+// IsEmpty returns true if there are no resources to delete.
+func (p TeardownPlan) IsEmpty() bool {
+	return len(p.Topics) == 0 && len(p.ConsumerGroups) == 0 && len(p.ACLs) == 0 && len(p.Subjects) == 0
+}
+
+// Print writes a human-readable listing of all resources to delete.
+func (p TeardownPlan) Print(w io.Writer) {
+	fmt.Fprintf(w, "Resources of the shop with global prefix '%v':\n", p.GlobalPrefix)
+
+	printSection := func(title string, items []string) {
+		fmt.Fprintf(w, "\n%v (%d):\n", title, len(items))
+		for _, item := range items {
+			fmt.Fprintf(w, "  %v\n", item)
+		}
+	}
+	printSection("Topics", p.Topics)
+	printSection("Consumer groups", p.ConsumerGroups)
+	acls := make([]string, len(p.ACLs))
+	for i, acl := range p.ACLs {
+		acls[i] = fmt.Sprintf("%v %v %v on %v '%v' (%v) from host %v",
+			acl.Principal, acl.Permission, acl.Operation, acl.Type, acl.Name, acl.Pattern, acl.Host)
+	}
+	printSection("ACLs", acls)
+	printSection("Schema registry subjects", p.Subjects)
+}
+
+// Teardown discovers and deletes all resources that have been created by the shop
+// with the configured global prefix and topic names: topics, consumer groups, ACLs
+// and schema registry subjects.
+type Teardown struct {
+	cfg    config.Config
+	logger *zap.Logger
+
+	kafkaCl *kgo.Client
+	adminCl *kadm.Client
+	// srClient may be nil if schema registry hasn't been configured
+	srClient *franzsr.Client
+}
+
+// NewTeardown creates the clients that are required to teardown the shop.
+func NewTeardown(cfg config.Config, logger *zap.Logger) (*Teardown, error) {
+	if cfg.Sink.Type != config.SinkTypeKafka {
+		return nil, fmt.Errorf("teardown requires the kafka sink, but sink type is '%v'", cfg.Sink.Type)
+	}
+	if cfg.Shop.GlobalPrefix == "" {
+		return nil, fmt.Errorf("teardown requires a global prefix, otherwise all resources would be deleted")
+	}
+
+	kafkaFactory := kafka.NewFactory(cfg.Kafka, logger.Named("kafka_client"))
+	kafkaCl, err := kafkaFactory.NewKafkaClient(cfg.Shop.GlobalPrefix + "teardown")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka client: %w", err)
+	}
+
+	srClient, err := sr.NewFactory(cfg.SchemaRegistry, logger.Named("schema_registry")).NewSchemaRegistryClient()
+	if err != nil {
+		kafkaCl.Close()
+		return nil, fmt.Errorf("failed to create schema registry client: %w", err)
+	}
+
+	return &Teardown{
+		cfg:      cfg,
+		logger:   logger,
+		kafkaCl:  kafkaCl,
+		adminCl:  kadm.NewClient(kafkaCl),
+		srClient: srClient,
+	}, nil
+}
+
+// Close closes all clients.
+func (t *Teardown) Close() {
+	t.kafkaCl.Close()
+}
+
+// Plan discovers all resources that belong to the shop.
+func (t *Teardown) Plan(ctx context.Context) (TeardownPlan, error) {
+	plan := TeardownPlan{GlobalPrefix: t.cfg.Shop.GlobalPrefix}
+	shopTopics := teardownTopics(t.cfg.Shop)
+
+	topics, err := t.adminCl.ListTopics(ctx)
+	if err != nil {
+		return TeardownPlan{}, fmt.Errorf("failed to list topics: %w", err)
+	}
+	plan.Topics = filterNames(topics.Names(), shopTopics)
+
+	groups, err := t.adminCl.ListGroups(ctx)
+	if err != nil {
+		return TeardownPlan{}, fmt.Errorf("failed to list consumer groups: %w", err)
+	}
+	plan.ConsumerGroups = filterNames(groups.Groups(), teardownConsumerGroups(t.cfg.Shop))
+
+	// ACLs are created for principals that are named after the services,
+	// hence we discover them by the principal's name.
+	principals := make(map[string]struct{})
+	for _, principal := range teardownPrincipals(t.cfg.Shop) {
+		principals[principal] = struct{}{}
+	}
+	allACLs := kadm.NewACLs().
+		AnyResource().
+		ResourcePatternType(kadm.ACLPatternAny).
+		Allow().AllowHosts().
+		Deny().DenyHosts().
+		Operations()
+	results, err := t.adminCl.DescribeACLs(ctx, allACLs)
+	if err != nil {
+		// Describing ACLs fails if the cluster has no authorizer configured
+		t.logger.Info("failed to describe ACLs, skipping ACLs", zap.Error(err))
+	}
+	for _, result := range results {
+		if result.Err != nil {
+			t.logger.Info("failed to describe ACLs, skipping ACLs", zap.Error(result.Err))
+			continue
+		}
+		for _, acl := range result.Described {
+			if _, ok := principals[acl.Principal]; ok {
+				plan.ACLs = append(plan.ACLs, acl)
+			}
+		}
+	}
+
+	if t.srClient != nil {
+		subjects, err := t.srClient.Subjects(franzsr.WithParams(ctx, franzsr.ShowDeleted))
+		if err != nil {
+			return TeardownPlan{}, fmt.Errorf("failed to list schema registry subjects: %w", err)
+		}
+		plan.Subjects = planSubjects(subjects, shopTopics, t.sharedSubjects())
+	}
+
+	return plan, nil
+}
+
+// teardownTopics returns the names of all topics the shop produces to.
+func teardownTopics(cfg config.Shop) []string {
+	topics := make([]string, 0, len(config.ShopTopicKeys()))
+	for _, key := range config.ShopTopicKeys() {
+		topics = append(topics, cfg.Topic(key).Name)
+	}
+	return topics
+}
+
+// teardownConsumerGroups returns the consumer groups of all services that
+// consume records.
+func teardownConsumerGroups(cfg config.Shop) []string {
+	return []string{
+		cfg.GlobalPrefix + "address-service",
+		cfg.GlobalPrefix + "delivery-service",
+		cfg.GlobalPrefix + "order-service",
+		cfg.GlobalPrefix + "payment-service",
+	}
+}
+
+// teardownPrincipals returns the principals the meta service creates ACLs for.
+func teardownPrincipals(cfg config.Shop) []string {
+	return []string{
+		"User:" + cfg.GlobalPrefix + "meta-service",
+		"User:" + cfg.GlobalPrefix + "delivery-service",
+	}
+}
+
+// topicSubjects returns all subjects that may be registered for the given topics
+// with a subject name strategy that is scoped to the topic.
+func topicSubjects(topics []string) []string {
+	suffixes := []string{"value", "key"}
+	for _, messageTypes := range [][]string{referencingMessageTypes, referencedMessageTypes, keyMessageTypes} {
+		for _, messageType := range messageTypes {
+			suffixes = append(suffixes, recordNames(messageType)...)
+		}
+	}
+
+	subjects := make([]string, 0, len(topics)*len(suffixes))
+	for _, topic := range topics {
+		for _, suffix := range suffixes {
+			subjects = append(subjects, topic+"-"+suffix)
+		}
+	}
+	return subjects
+}
+
+// planSubjects returns the existing subjects that belong to the given topics,
+// followed by the existing shared subjects. Referenced subjects are ordered
+// after the subjects that may reference them.
+func planSubjects(existing []string, topics []string, shared []string) []string {
+	subjects := filterNames(existing, topicSubjects(topics))
+	// Referenced subjects can only be deleted once the referencing subjects are gone
+	sort.SliceStable(subjects, func(i, j int) bool {
+		return !isReferencedSubject(subjects[i]) && isReferencedSubject(subjects[j])
+	})
+	for _, subject := range shared {
+		for _, existingSubject := range existing {
+			if subject == existingSubject {
+				subjects = append(subjects, subject)
+				break
+			}
+		}
+	}
+
+	return subjects
+}
+
+// Execute deletes all resources of the given plan. It tries to delete as many
+// resources as possible and returns all errors that occurred.
+func (t *Teardown) Execute(ctx context.Context, plan TeardownPlan) error {
+	var errs []error
+
+	if len(plan.Topics) > 0 {
+		responses, err := t.adminCl.DeleteTopics(ctx, plan.Topics...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete topics: %w", err))
+		}
+		for _, res := range responses.Sorted() {
+			if res.Err != nil {
+				errs = append(errs, fmt.Errorf("failed to delete topic '%v': %w", res.Topic, res.Err))
+				continue
+			}
+			t.logger.Info("deleted topic", zap.String("topic_name", res.Topic))
+		}
+	}
+
+	if len(plan.ConsumerGroups) > 0 {
+		responses, err := t.adminCl.DeleteGroups(ctx, plan.ConsumerGroups...)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete consumer groups: %w", err))
+		}
+		for _, res := range responses.Sorted() {
+			if res.Err != nil {
+				errs = append(errs, fmt.Errorf("failed to delete consumer group '%v': %w", res.Group, res.Err))
+				continue
+			}
+			t.logger.Info("deleted consumer group", zap.String("group", res.Group))
+		}
+	}
+
+	if len(plan.ACLs) > 0 {
+		principalSet := make(map[string]struct{})
+		for _, acl := range plan.ACLs {
+			principalSet[acl.Principal] = struct{}{}
+		}
+		principals := make([]string, 0, len(principalSet))
+		for principal := range principalSet {
+			principals = append(principals, principal)
+		}
+		sort.Strings(principals)
+
+		principalACLs := kadm.NewACLs().
+			AnyResource().
+			ResourcePatternType(kadm.ACLPatternAny).
+			Allow(principals...).AllowHosts().
+			Deny(principals...).DenyHosts().
+			Operations()
+		results, err := t.adminCl.DeleteACLs(ctx, principalACLs)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to delete ACLs: %w", err))
+		}
+		for _, result := range results {
+			if result.Err != nil {
+				errs = append(errs, fmt.Errorf("failed to delete ACLs: %w", result.Err))
+				continue
+			}
+			for _, deleted := range result.Deleted {
+				if deleted.Err != nil {
+					errs = append(errs, fmt.Errorf("failed to delete ACL of principal '%v': %w", deleted.Principal, deleted.Err))
+					continue
+				}
+				t.logger.Info("deleted ACL",
+					zap.String("principal", deleted.Principal),
+					zap.String("resource_type", deleted.Type.String()),
+					zap.String("resource_name", deleted.Name),
+					zap.String("operation", deleted.Operation.String()))
+			}
+		}
+	}
+
+	for _, subject := range plan.Subjects {
+		if err := t.deleteSubject(ctx, subject); err != nil {
+			if t.isSharedSubject(subject) {
+				// Shared subjects may still be referenced by shops with a different prefix
+				t.logger.Info("keeping shared schema registry subject", zap.String("subject", subject), zap.Error(err))
+				continue
+			}
+			errs = append(errs, fmt.Errorf("failed to delete schema registry subject '%v': %w", subject, err))
+			continue
+		}
+		t.logger.Info("deleted schema registry subject", zap.String("subject", subject))
+	}
+
+	return errors.Join(errs...)
+}
+
+// deleteSubject permanently deletes the subject. Subjects must be soft deleted before
+// they can be hard deleted, the soft deletion fails if it has been soft deleted before.
+func (t *Teardown) deleteSubject(ctx context.Context, subject string) error {
+	_, softErr := t.srClient.DeleteSubject(ctx, subject, franzsr.SoftDelete)
+	if _, err := t.srClient.DeleteSubject(ctx, subject, franzsr.HardDelete); err != nil {
+		return errors.Join(softErr, err)
+	}
+
+	return nil
+}
+
+// sharedSubjects are the schema registry subjects that are not scoped to the
+// global prefix. They are only deleted if no other schema references them.
+func (t *Teardown) sharedSubjects() []string {
+	return newSubjectNamer(t.cfg.SchemaRegistry).sharedSubjects()
+}
+
+func (t *Teardown) isSharedSubject(subject string) bool {
+	for _, shared := range t.sharedSubjects() {
+		if subject == shared {
+			return true
+		}
+	}
+	return false
+}
+
+// filterNames returns the sorted names that are one of the wanted names.
+func filterNames(names []string, wanted []string) []string {
+	wantedSet := make(map[string]struct{}, len(wanted))
+	for _, name := range wanted {
+		wantedSet[name] = struct{}{}
+	}
+
+	var filtered []string
+	for _, name := range names {
+		if _, ok := wantedSet[name]; ok {
+			filtered = append(filtered, name)
+		}
+	}
+	sort.Strings(filtered)
+
+	return filtered
+}
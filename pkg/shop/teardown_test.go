@@ -0,0 +1,87 @@
+package shop
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+func TestPlanSubjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing []string
+		shared   []string
+		want     []string
+	}{
+		{
+			name: "referenced subjects are deleted last",
+			existing: []string{
+				"owlshop-orders-avro-sr-com.shop.v1.avro.Customer",
+				"owlshop-orders-avro-sr-com.shop.v1.avro.Order",
+				"owlshop-orders-protobuf-sr-shop.v1.Address",
+				"owlshop-orders-protobuf-sr-shop.v1.Order",
+				"other-orders-avro-sr-com.shop.v1.avro.Order",
+				"owlshop-alice-orders-avro-sr-com.shop.v1.avro.Order",
+				"owlshop-orders-avro-sr-unknown",
+			},
+			want: []string{
+				"owlshop-orders-avro-sr-com.shop.v1.avro.Order",
+				"owlshop-orders-protobuf-sr-shop.v1.Order",
+				"owlshop-orders-avro-sr-com.shop.v1.avro.Customer",
+				"owlshop-orders-protobuf-sr-shop.v1.Address",
+			},
+		},
+		{
+			name:     "only existing shared subjects are deleted",
+			existing: []string{"owlshop-orders-value", subjectCustomerAvro, "other-orders-value", "owlshop-alice-orders-value"},
+			shared:   []string{subjectCustomerProto, subjectCustomerAvro},
+			want:     []string{"owlshop-orders-value", subjectCustomerAvro},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			topics := teardownTopics(config.Shop{GlobalPrefix: "owlshop-"})
+			if got := planSubjects(tt.existing, topics, tt.shared); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("planSubjects() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTeardownPlanIsEmpty(t *testing.T) {
+	if !(TeardownPlan{GlobalPrefix: "owlshop-"}).IsEmpty() {
+		t.Errorf("IsEmpty() = false for a plan without resources")
+	}
+	if (TeardownPlan{GlobalPrefix: "owlshop-", Subjects: []string{"owlshop-orders-value"}}).IsEmpty() {
+		t.Errorf("IsEmpty() = true for a plan with subjects")
+	}
+}
+
+func TestTeardownExcludesOtherShops(t *testing.T) {
+	shop := config.Shop{GlobalPrefix: "owlshop-"}
+	// The prefix of this shop starts with the prefix of the shop to tear down
+	other := config.Shop{GlobalPrefix: "owlshop-alice-"}
+
+	tests := []struct {
+		name  string
+		names func(config.Shop) []string
+	}{
+		{name: "topics", names: teardownTopics},
+		{name: "consumer groups", names: teardownConsumerGroups},
+		{name: "principals", names: teardownPrincipals},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := tt.names(shop)
+			existing := append(tt.names(other), want...)
+
+			got := filterNames(existing, want)
+			sort.Strings(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("filterNames() = %v, want %v", got, want)
+			}
+		})
+	}
+}
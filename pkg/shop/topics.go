@@ -0,0 +1,30 @@
+package shop
+
+import (
+	"github.com/twmb/franz-go/pkg/kadm"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+	"github.com/cloudhut/owl-shop/pkg/sink"
+)
+
+// newSinkTopic returns the topic that shall be created for the given logical
+// topic. Configured topic configs take precedence over the default configs.
+func newSinkTopic(cfg config.Shop, key string, format sink.Format, defaultConfigs map[string]string) sink.Topic {
+	topicCfg := cfg.Topic(key)
+
+	configs := make(map[string]*string, len(defaultConfigs)+len(topicCfg.Configs))
+	for name, value := range defaultConfigs {
+		configs[name] = kadm.StringPtr(value)
+	}
+	for name, value := range topicCfg.Configs {
+		configs[name] = kadm.StringPtr(value)
+	}
+
+	return sink.Topic{
+		Name:              topicCfg.Name,
+		Partitions:        topicCfg.Partitions,
+		ReplicationFactor: topicCfg.ReplicationFactor,
+		Configs:           configs,
+		Format:            format,
+	}
+}
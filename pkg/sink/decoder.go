@@ -0,0 +1,81 @@
+package sink
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/hamba/avro/v2"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// decoder decodes record keys or values of a single format into their JSON
+// representation, so that they can be inspected.
+type decoder struct {
+	format      Format
+	schema      string
+	messageType protoreflect.MessageType
+
+	// avroSchema is parsed lazily, because named types that are referenced by
+	// the schema may only be parsed after the topic has been created.
+	avroSchemaOnce sync.Once
+	avroSchema     avro.Schema
+	avroSchemaErr  error
+}
+
+func newValueDecoder(topic Topic) *decoder {
+	return &decoder{format: topic.Format, schema: topic.Schema, messageType: topic.MessageType}
+}
+
+func newKeyDecoder(topic Topic) *decoder {
+	format := topic.KeyFormat
+	if format == "" {
+		format = FormatString
+	}
+	return &decoder{format: format, schema: topic.KeySchema, messageType: topic.KeyMessageType}
+}
+
+// decode returns the JSON representation of the given key or value. Data of
+// the string format is returned as JSON string.
+func (d *decoder) decode(data []byte) (json.RawMessage, error) {
+	if data == nil {
+		return nil, nil
+	}
+
+	payload, err := stripSchemaRegistryHeader(d.format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	switch d.format {
+	case FormatString:
+		return json.Marshal(string(payload))
+	case FormatJSON, FormatJSONSR:
+		return payload, nil
+	case FormatProtobuf, FormatProtobufSR:
+		if d.messageType == nil {
+			return nil, fmt.Errorf("no protobuf message type")
+		}
+		msg := d.messageType.New().Interface()
+		if err := proto.Unmarshal(payload, msg); err != nil {
+			return nil, fmt.Errorf("failed to deserialize protobuf message: %w", err)
+		}
+		return protojson.Marshal(msg)
+	case FormatAvroSR:
+		d.avroSchemaOnce.Do(func() {
+			d.avroSchema, d.avroSchemaErr = avro.Parse(d.schema)
+		})
+		if d.avroSchemaErr != nil {
+			return nil, fmt.Errorf("failed to parse avro schema: %w", d.avroSchemaErr)
+		}
+		var decoded any
+		if err := avro.Unmarshal(d.avroSchema, payload, &decoded); err != nil {
+			return nil, fmt.Errorf("failed to deserialize avro record: %w", err)
+		}
+		return json.Marshal(decoded)
+	}
+
+	return nil, fmt.Errorf("unsupported format '%v'", d.format)
+}
@@ -0,0 +1,76 @@
+package sink
+
+import (
+	"context"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"go.uber.org/zap"
+
+	"github.com/cloudhut/owl-shop/pkg/kafka"
+)
+
+// Kafka is a Sink that produces all records to a Kafka cluster.
+type Kafka struct {
+	client        *kgo.Client
+	logger        *zap.Logger
+	reconcileMode string
+}
+
+// NewKafka creates a new Sink that produces records with the given client.
+// Existing topics are reconciled according to the given reconcile mode.
+// Closing the sink closes the client.
+func NewKafka(client *kgo.Client, logger *zap.Logger, reconcileMode string) *Kafka {
+	return &Kafka{client: client, logger: logger, reconcileMode: reconcileMode}
+}
+
+// CreateTopic reconciles the topic in the Kafka cluster.
+func (k *Kafka) CreateTopic(ctx context.Context, topic Topic) error {
+	return kafka.ReconcileTopic(
+		ctx,
+		k.client,
+		k.logger,
+		k.reconcileMode,
+		topic.Name,
+		topic.Partitions,
+		topic.ReplicationFactor,
+		topic.Configs,
+	)
+}
+
+// Produce produces the record to Kafka.
+func (k *Kafka) Produce(ctx context.Context, rec *kgo.Record, promise func(*kgo.Record, error)) {
+	k.client.Produce(ctx, rec, promise)
+}
+
+// Flush blocks until all buffered records have been produced.
+func (k *Kafka) Flush(ctx context.Context) error {
+	return k.client.Flush(ctx)
+}
+
+// Close closes the Kafka client.
+func (k *Kafka) Close() {
+	k.client.Close()
+}
+
+// KafkaFactory creates Kafka sinks with a dedicated client for each service.
+type KafkaFactory struct {
+	kafkaFactory  *kafka.Factory
+	logger        *zap.Logger
+	reconcileMode string
+}
+
+// NewKafkaFactory creates a new KafkaFactory.
+func NewKafkaFactory(kafkaFactory *kafka.Factory, logger *zap.Logger, reconcileMode string) *KafkaFactory {
+	return &KafkaFactory{kafkaFactory: kafkaFactory, logger: logger, reconcileMode: reconcileMode}
+}
+
+// NewSink creates a new Kafka client with the given client id and returns a
+// Sink that produces with that client.
+func (f *KafkaFactory) NewSink(clientID string) (Sink, error) {
+	client, err := f.kafkaFactory.NewKafkaClient(clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKafka(client, f.logger, f.reconcileMode), nil
+}
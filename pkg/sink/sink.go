@@ -0,0 +1,193 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+	"github.com/twmb/franz-go/pkg/sr"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Format describes how the record values of a topic are encoded. Sinks that
+// don't write to Kafka use it to choose an appropriate file format.
+type Format string
+
+const (
+	// FormatString is used for UTF-8 string record keys.
+	FormatString Format = "string"
+	// FormatJSON is used for JSON encoded record values.
+	FormatJSON Format = "json"
+	// FormatProtobuf is used for serialized protobuf messages without any
+	// schema registry framing.
+	FormatProtobuf Format = "protobuf"
+	// FormatProtobufSR is used for protobuf messages that are prefixed with
+	// the schema registry wire format header.
+	FormatProtobufSR Format = "protobuf-sr"
+	// FormatAvroSR is used for avro records that are prefixed with the schema
+	// registry wire format header.
+	FormatAvroSR Format = "avro-sr"
+	// FormatJSONSR is used for JSON encoded record values that are prefixed
+	// with the schema registry wire format header.
+	FormatJSONSR Format = "json-sr"
+)
+
+// Topic describes a topic that records are produced to.
+type Topic struct {
+	Name              string
+	Partitions        int32
+	ReplicationFactor int16
+	Configs           map[string]*string
+
+	Format Format
+	// Schema is the schema of the record values. It is set to the avro schema
+	// for topics with FormatAvroSR, where it is required, and to the JSON
+	// schema for topics with FormatJSONSR.
+	Schema string
+	// MessageType is the protobuf message type of the record values. It is
+	// only used to decode records of topics with FormatProtobuf or
+	// FormatProtobufSR for inspection.
+	MessageType protoreflect.MessageType
+
+	// KeyFormat describes how the record keys are encoded. Defaults to
+	// FormatString if not set.
+	KeyFormat Format
+	// KeySchema is the avro schema of the record keys. It is only required
+	// for topics with a FormatAvroSR key format.
+	KeySchema string
+	// KeyMessageType is the protobuf message type of the record keys. It is
+	// only used to decode protobuf keys for inspection.
+	KeyMessageType protoreflect.MessageType
+}
+
+// Sink is the destination of all records produced by the shop's services.
+type Sink interface {
+	// CreateTopic ensures the given topic exists before records are produced.
+	CreateTopic(ctx context.Context, topic Topic) error
+
+	// Produce asynchronously writes the given record. The promise is called
+	// once the record has been written or failed to be written.
+	Produce(ctx context.Context, rec *kgo.Record, promise func(*kgo.Record, error))
+
+	// Flush blocks until all produced records have been written.
+	Flush(ctx context.Context) error
+
+	// Close releases all resources of the sink. Records that have not been
+	// flushed may be lost.
+	Close()
+}
+
+// Factory creates a Sink for a service, identified by its client id.
+type Factory interface {
+	NewSink(clientID string) (Sink, error)
+}
+
+// Local is a Sink that does not write records to Kafka. Because there is no
+// Kafka cluster, services that would usually consume records from a topic
+// can subscribe to the produced records instead.
+type Local interface {
+	Sink
+	Factory
+
+	// Subscribe registers a function that is called for each record that has
+	// been successfully written to the given topic.
+	Subscribe(topic string, fn func(*kgo.Record))
+}
+
+// NopCloser returns a Sink that does not close the underlying sink, so that a
+// single sink can be shared by several services.
+func NopCloser(s Sink) Sink {
+	return nopCloser{s}
+}
+
+type nopCloser struct {
+	Sink
+}
+
+func (nopCloser) Close() {}
+
+// subscribers keeps track of the functions that have subscribed to the
+// records of a Local sink.
+type subscribers struct {
+	mu  sync.RWMutex
+	fns map[string][]func(*kgo.Record)
+}
+
+func (s *subscribers) Subscribe(topic string, fn func(*kgo.Record)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.fns == nil {
+		s.fns = make(map[string][]func(*kgo.Record))
+	}
+	s.fns[topic] = append(s.fns[topic], fn)
+}
+
+func (s *subscribers) notify(rec *kgo.Record) {
+	s.mu.RLock()
+	fns := s.fns[rec.Topic]
+	s.mu.RUnlock()
+
+	for _, fn := range fns {
+		fn(rec)
+	}
+}
+
+// jsonRecord is the JSON representation of a record.
+type jsonRecord struct {
+	Topic     string            `json:"topic,omitempty"`
+	Key       json.RawMessage   `json:"key"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	Timestamp time.Time         `json:"timestamp"`
+	Value     json.RawMessage   `json:"value"`
+}
+
+func newJSONRecord(rec *kgo.Record, key, value json.RawMessage) jsonRecord {
+	r := jsonRecord{
+		Key:       key,
+		Timestamp: rec.Timestamp,
+		Value:     value,
+	}
+	if len(rec.Headers) > 0 {
+		r.Headers = make(map[string]string, len(rec.Headers))
+		for _, header := range rec.Headers {
+			r.Headers[header.Key] = string(header.Value)
+		}
+	}
+
+	return r
+}
+
+// stripSchemaRegistryHeader returns the payload of a record value that has
+// been serialized with the schema registry wire format. Values of all other
+// formats are returned unchanged.
+func stripSchemaRegistryHeader(format Format, value []byte) ([]byte, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	var header sr.ConfluentHeader
+	switch format {
+	case FormatProtobufSR:
+		_, payload, err := header.DecodeID(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode schema id: %w", err)
+		}
+		_, payload, err = header.DecodeIndex(payload, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode message index: %w", err)
+		}
+		return payload, nil
+	case FormatAvroSR, FormatJSONSR:
+		_, payload, err := header.DecodeID(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode schema id: %w", err)
+		}
+		return payload, nil
+	}
+
+	return value, nil
+}
@@ -0,0 +1,181 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+const (
+	// StdoutFormatText prints each record as a human-readable block.
+	StdoutFormatText = "text"
+	// StdoutFormatJSON prints each record as a single line JSON object.
+	StdoutFormatJSON = "json"
+)
+
+// Stdout is a Sink that prints all records with their decoded values to a
+// writer (usually stdout) instead of writing them to Kafka. It is meant for
+// inspecting the generated records, hence creating topics has no effect.
+type Stdout struct {
+	subscribers
+
+	format string
+
+	writerMu sync.Mutex
+	writer   io.Writer
+
+	topicsMu sync.RWMutex
+	topics   map[string]*stdoutTopic
+}
+
+type stdoutTopic struct {
+	keyDecoder   *decoder
+	valueDecoder *decoder
+}
+
+// NewStdout creates a new Stdout sink that prints records in the given format
+// to the writer.
+func NewStdout(writer io.Writer, format string) (*Stdout, error) {
+	if format != StdoutFormatText && format != StdoutFormatJSON {
+		return nil, fmt.Errorf("unsupported format '%v'", format)
+	}
+
+	return &Stdout{
+		format: format,
+		writer: writer,
+		topics: make(map[string]*stdoutTopic),
+	}, nil
+}
+
+// NewSink returns the shared Stdout sink. Closing the returned sink has no effect.
+func (s *Stdout) NewSink(_ string) (Sink, error) {
+	return NopCloser(s), nil
+}
+
+// CreateTopic only remembers the topic, so that its records can be decoded.
+func (s *Stdout) CreateTopic(_ context.Context, topic Topic) error {
+	s.topicsMu.Lock()
+	defer s.topicsMu.Unlock()
+
+	if _, exists := s.topics[topic.Name]; !exists {
+		s.topics[topic.Name] = &stdoutTopic{
+			keyDecoder:   newKeyDecoder(topic),
+			valueDecoder: newValueDecoder(topic),
+		}
+	}
+
+	return nil
+}
+
+// Produce synchronously prints the record and then calls the promise and all
+// subscribers of the topic.
+func (s *Stdout) Produce(_ context.Context, rec *kgo.Record, promise func(*kgo.Record, error)) {
+	if rec.Timestamp.IsZero() {
+		rec.Timestamp = time.Now()
+	}
+
+	s.topicsMu.RLock()
+	topic, exists := s.topics[rec.Topic]
+	s.topicsMu.RUnlock()
+	if !exists {
+		promise(rec, fmt.Errorf("topic '%v' has not been created", rec.Topic))
+		return
+	}
+
+	if err := s.print(topic, rec); err != nil {
+		promise(rec, err)
+		return
+	}
+	promise(rec, nil)
+	s.notify(rec)
+}
+
+// Flush has no effect, because records are printed synchronously.
+func (*Stdout) Flush(_ context.Context) error {
+	return nil
+}
+
+// Close has no effect. The writer must be closed by the caller.
+func (*Stdout) Close() {}
+
+func (s *Stdout) print(topic *stdoutTopic, rec *kgo.Record) error {
+	key, err := topic.keyDecoder.decode(rec.Key)
+	if err != nil {
+		return fmt.Errorf("failed to decode record key: %w", err)
+	}
+	value, err := topic.valueDecoder.decode(rec.Value)
+	if err != nil {
+		return fmt.Errorf("failed to decode record value: %w", err)
+	}
+
+	record := newJSONRecord(rec, key, value)
+	record.Topic = rec.Topic
+
+	var out []byte
+	switch s.format {
+	case StdoutFormatJSON:
+		out, err = json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to serialize record: %w", err)
+		}
+		out = append(out, '\n')
+	default:
+		out, err = formatText(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.writerMu.Lock()
+	defer s.writerMu.Unlock()
+	_, err = s.writer.Write(out)
+	return err
+}
+
+// formatText formats the record as a header line followed by the indented value.
+func formatText(record jsonRecord) ([]byte, error) {
+	var sb strings.Builder
+	sb.WriteString("topic=" + record.Topic)
+	if record.Key != nil {
+		// String keys are printed without quotes, structured keys as JSON
+		var key string
+		if err := json.Unmarshal(record.Key, &key); err != nil {
+			key = string(record.Key)
+		}
+		sb.WriteString(" key=" + key)
+	} else {
+		sb.WriteString(" key=<null>")
+	}
+	sb.WriteString(" timestamp=" + record.Timestamp.Format(time.RFC3339Nano))
+	if len(record.Headers) > 0 {
+		headerKeys := make([]string, 0, len(record.Headers))
+		for key := range record.Headers {
+			headerKeys = append(headerKeys, key)
+		}
+		sort.Strings(headerKeys)
+		for _, key := range headerKeys {
+			sb.WriteString(" header." + key + "=" + record.Headers[key])
+		}
+	}
+	sb.WriteString("\n")
+
+	if record.Value == nil {
+		sb.WriteString("<tombstone>\n\n")
+		return []byte(sb.String()), nil
+	}
+	value, err := json.MarshalIndent(record.Value, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format record value: %w", err)
+	}
+	sb.Write(value)
+	sb.WriteString("\n\n")
+
+	return []byte(sb.String()), nil
+}
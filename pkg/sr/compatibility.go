@@ -0,0 +1,100 @@
+package sr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/sr"
+)
+
+// CompatibilityClient registers schemas with a schema registry client. It sets the
+// configured compatibility level on each subject before the first schema is
+// registered under it, and explains rejected registrations with the registry's
+// compatibility messages.
+type CompatibilityClient struct {
+	client *sr.Client
+	// level is zero if the registry's default compatibility shall be kept
+	level sr.CompatibilityLevel
+
+	mu         sync.Mutex
+	configured map[string]struct{}
+}
+
+// NewCompatibilityClient wraps the given client. An empty compatibility keeps the
+// compatibility levels of the subjects untouched.
+func NewCompatibilityClient(client *sr.Client, compatibility string) (*CompatibilityClient, error) {
+	c := &CompatibilityClient{
+		client:     client,
+		configured: make(map[string]struct{}),
+	}
+	if compatibility != "" {
+		if err := c.level.UnmarshalText([]byte(compatibility)); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// CreateSchema registers the schema under the given subject after its compatibility
+// level has been set.
+func (c *CompatibilityClient) CreateSchema(ctx context.Context, subject string, s sr.Schema) (sr.SubjectSchema, error) {
+	if err := c.setCompatibility(ctx, subject); err != nil {
+		return sr.SubjectSchema{}, err
+	}
+
+	subjectSchema, err := c.client.CreateSchema(ctx, subject, s)
+	if err != nil && IsIncompatible(err) {
+		reasons := IncompatibilityReasons(ctx, c.client, subject, s)
+		if len(reasons) == 0 {
+			return sr.SubjectSchema{}, fmt.Errorf("schema is incompatible with subject '%v': %w", subject, err)
+		}
+		return sr.SubjectSchema{}, fmt.Errorf("schema is incompatible with subject '%v': %w (%v)",
+			subject, err, strings.Join(reasons, "; "))
+	}
+
+	return subjectSchema, err
+}
+
+func (c *CompatibilityClient) setCompatibility(ctx context.Context, subject string) error {
+	if c.level == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.configured[subject]; exists {
+		return nil
+	}
+	for _, result := range c.client.SetCompatibility(ctx, sr.SetCompatibility{Level: c.level}, subject) {
+		if result.Err != nil {
+			return fmt.Errorf("failed to set compatibility of subject '%v' to %v: %w", subject, c.level, result.Err)
+		}
+	}
+	c.configured[subject] = struct{}{}
+
+	return nil
+}
+
+// IsIncompatible returns true if the registry rejected a schema because it is
+// incompatible with the existing versions of the subject.
+func IsIncompatible(err error) bool {
+	var responseErr *sr.ResponseError
+	return errors.As(err, &responseErr) && responseErr.ErrorCode == http.StatusConflict
+}
+
+// IncompatibilityReasons asks the registry why the schema is incompatible with the
+// latest version of the subject. It returns no reasons if the registry doesn't
+// report any.
+func IncompatibilityReasons(ctx context.Context, client *sr.Client, subject string, s sr.Schema) []string {
+	result, err := client.CheckCompatibility(sr.WithParams(ctx, sr.Verbose), subject, -1, s)
+	if err != nil {
+		return []string{fmt.Sprintf("failed to check compatibility: %v", err)}
+	}
+
+	return result.Messages
+}
@@ -0,0 +1,51 @@
+package sr
+
+import (
+	"context"
+	"sync"
+
+	"github.com/twmb/franz-go/pkg/sr"
+)
+
+// InMemoryRegistry is a minimal schema registry that keeps all schemas in
+// memory. It is used when records are not written to Kafka, so that records
+// can still be encoded with the schema registry wire format. It does not
+// perform any compatibility checks.
+type InMemoryRegistry struct {
+	mu       sync.Mutex
+	nextID   int
+	subjects map[string][]sr.SubjectSchema
+}
+
+// NewInMemoryRegistry creates a new, empty InMemoryRegistry.
+func NewInMemoryRegistry() *InMemoryRegistry {
+	return &InMemoryRegistry{
+		nextID:   1,
+		subjects: make(map[string][]sr.SubjectSchema),
+	}
+}
+
+// CreateSchema registers the schema under the given subject. If the same schema
+// has already been registered for the subject, the existing version is returned.
+func (r *InMemoryRegistry) CreateSchema(_ context.Context, subject string, s sr.Schema) (sr.SubjectSchema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	versions := r.subjects[subject]
+	for _, existing := range versions {
+		if existing.Schema.Schema == s.Schema {
+			return existing, nil
+		}
+	}
+
+	subjectSchema := sr.SubjectSchema{
+		Subject: subject,
+		Version: len(versions) + 1,
+		ID:      r.nextID,
+		Schema:  s,
+	}
+	r.nextID++
+	r.subjects[subject] = append(versions, subjectSchema)
+
+	return subjectSchema, nil
+}
@@ -0,0 +1,130 @@
+package traffic
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+// Profile evaluates the configured traffic profile and returns the factor by
+// which the request rate shall be multiplied at a given point in time.
+type Profile struct {
+	cfg       config.ShopTraffic
+	startedAt time.Time
+
+	// spikeMu guards the state of random spikes, which changes on evaluation.
+	spikeMu         sync.Mutex
+	rng             *rand.Rand
+	spikeEndsAt     time.Time
+	lastEvaluatedAt time.Time
+}
+
+// NewProfile creates a new traffic profile. All time offsets (e.g. ramps) are
+// relative to the given start time. Random spikes are rolled with the given
+// seed, a seed of 0 uses the start time as seed.
+func NewProfile(cfg config.ShopTraffic, startedAt time.Time, seed int64) *Profile {
+	if seed == 0 {
+		seed = startedAt.UnixNano()
+	}
+
+	return &Profile{
+		cfg:             cfg,
+		startedAt:       startedAt,
+		rng:             rand.New(rand.NewSource(seed)),
+		lastEvaluatedAt: startedAt,
+	}
+}
+
+// Factor returns the factor by which the request rate shall be multiplied at
+// the given time. Calls must be made with monotonically increasing times,
+// because random spikes are rolled based on the time since the last call.
+func (p *Profile) Factor(now time.Time) float64 {
+	elapsed := now.Sub(p.startedAt)
+
+	return p.diurnalFactor(now) *
+		p.rampFactor(elapsed) *
+		p.burstFactor(elapsed) *
+		p.spikeFactor(now)
+}
+
+// diurnalFactor returns a cosine shaped factor that is 1 at the configured peak
+// hour and the configured trough factor 12 hours later.
+func (p *Profile) diurnalFactor(now time.Time) float64 {
+	if !p.cfg.Diurnal.Enabled {
+		return 1
+	}
+
+	hourOfDay := float64(now.Hour()) + float64(now.Minute())/60 + float64(now.Second())/3600
+	hoursSincePeak := hourOfDay - float64(p.cfg.Diurnal.PeakHour)
+	// Ranges from 0 (trough) to 1 (peak)
+	curve := (1 + math.Cos(2*math.Pi*hoursSincePeak/24)) / 2
+
+	trough := p.cfg.Diurnal.TroughFactor
+	return trough + (1-trough)*curve
+}
+
+// rampFactor returns the factor of the most recently started ramp. Before the
+// first ramp starts the factor is 1.
+func (p *Profile) rampFactor(elapsed time.Duration) float64 {
+	factor := 1.0
+	for _, ramp := range p.cfg.Ramps {
+		if elapsed < ramp.After {
+			break
+		}
+
+		sinceRampStart := elapsed - ramp.After
+		if ramp.Duration == 0 || sinceRampStart >= ramp.Duration {
+			factor = ramp.To
+			continue
+		}
+		progress := float64(sinceRampStart) / float64(ramp.Duration)
+		factor = ramp.From + (ramp.To-ramp.From)*progress
+	}
+
+	return factor
+}
+
+// burstFactor multiplies the factors of all currently active bursts.
+func (p *Profile) burstFactor(elapsed time.Duration) float64 {
+	factor := 1.0
+	for _, burst := range p.cfg.Bursts {
+		if elapsed < burst.Offset {
+			continue
+		}
+		if (elapsed-burst.Offset)%burst.Every < burst.Duration {
+			factor *= burst.Factor
+		}
+	}
+
+	return factor
+}
+
+// spikeFactor returns the spike factor if a random spike is currently active.
+// Spike starts follow a poisson process, so that on average one spike starts
+// every configured average interval.
+func (p *Profile) spikeFactor(now time.Time) float64 {
+	if !p.cfg.Spikes.Enabled {
+		return 1
+	}
+
+	p.spikeMu.Lock()
+	defer p.spikeMu.Unlock()
+
+	sinceLastEvaluation := now.Sub(p.lastEvaluatedAt)
+	p.lastEvaluatedAt = now
+
+	if now.Before(p.spikeEndsAt) {
+		return p.cfg.Spikes.Factor
+	}
+
+	spikeProbability := 1 - math.Exp(-float64(sinceLastEvaluation)/float64(p.cfg.Spikes.AverageInterval))
+	if p.rng.Float64() < spikeProbability {
+		p.spikeEndsAt = now.Add(p.cfg.Spikes.Duration)
+		return p.cfg.Spikes.Factor
+	}
+
+	return 1
+}
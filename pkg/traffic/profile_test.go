@@ -0,0 +1,104 @@
+package traffic
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cloudhut/owl-shop/pkg/config"
+)
+
+var startedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestProfileFactor(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.ShopTraffic
+		elapsed time.Duration
+		want    float64
+	}{
+		{name: "constant", elapsed: time.Hour, want: 1},
+		{
+			name:    "before ramp",
+			cfg:     config.ShopTraffic{Ramps: []config.ShopTrafficRamp{{After: time.Minute, Duration: time.Minute, From: 1, To: 3}}},
+			elapsed: 30 * time.Second,
+			want:    1,
+		},
+		{
+			name:    "during ramp",
+			cfg:     config.ShopTraffic{Ramps: []config.ShopTrafficRamp{{After: time.Minute, Duration: time.Minute, From: 1, To: 3}}},
+			elapsed: 90 * time.Second,
+			want:    2,
+		},
+		{
+			name:    "after ramp",
+			cfg:     config.ShopTraffic{Ramps: []config.ShopTrafficRamp{{After: time.Minute, Duration: time.Minute, From: 1, To: 3}}},
+			elapsed: time.Hour,
+			want:    3,
+		},
+		{
+			name:    "during burst",
+			cfg:     config.ShopTraffic{Bursts: []config.ShopTrafficBurst{{Every: time.Hour, Duration: time.Minute, Factor: 4}}},
+			elapsed: time.Hour + 30*time.Second,
+			want:    4,
+		},
+		{
+			name:    "between bursts",
+			cfg:     config.ShopTraffic{Bursts: []config.ShopTrafficBurst{{Every: time.Hour, Duration: time.Minute, Factor: 4}}},
+			elapsed: time.Hour + 2*time.Minute,
+			want:    1,
+		},
+		{
+			name:    "diurnal peak",
+			cfg:     config.ShopTraffic{Diurnal: config.ShopTrafficDiurnal{Enabled: true, PeakHour: 18, TroughFactor: 0.2}},
+			elapsed: 18 * time.Hour,
+			want:    1,
+		},
+		{
+			name:    "diurnal trough",
+			cfg:     config.ShopTraffic{Diurnal: config.ShopTrafficDiurnal{Enabled: true, PeakHour: 18, TroughFactor: 0.2}},
+			elapsed: 6 * time.Hour,
+			want:    0.2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile := NewProfile(tt.cfg, startedAt, 1)
+			if got := profile.Factor(startedAt.Add(tt.elapsed)); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Factor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileSpikesAreSeeded(t *testing.T) {
+	cfg := config.ShopTraffic{Spikes: config.ShopTrafficSpikes{
+		Enabled:         true,
+		AverageInterval: time.Minute,
+		Duration:        10 * time.Second,
+		Factor:          5,
+	}}
+
+	factors := func(seed int64) []float64 {
+		profile := NewProfile(cfg, startedAt, seed)
+		factors := make([]float64, 0, 600)
+		for i := 1; i <= cap(factors); i++ {
+			factors = append(factors, profile.Factor(startedAt.Add(time.Duration(i)*time.Second)))
+		}
+		return factors
+	}
+
+	first, second := factors(42), factors(42)
+	spikes := 0
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("factors differ at second %d: %v != %v", i+1, first[i], second[i])
+		}
+		if first[i] == cfg.Spikes.Factor {
+			spikes++
+		}
+	}
+	if spikes == 0 {
+		t.Errorf("expected spikes within 10 average intervals")
+	}
+}
@@ -0,0 +1,51 @@
+package traffic
+
+import (
+	"math"
+	"time"
+)
+
+// TokenBucket is a token bucket whose refill rate may change on every call.
+// It is not safe for concurrent use.
+type TokenBucket struct {
+	// maxBurst is the duration worth of tokens the bucket can hold. Tokens
+	// that exceed the capacity are discarded, so that a stalled caller does
+	// not cause an unbounded burst once it resumes.
+	maxBurst   time.Duration
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewTokenBucket creates an empty token bucket that can hold up to maxBurst
+// worth of tokens.
+func NewTokenBucket(maxBurst time.Duration, now time.Time) *TokenBucket {
+	return &TokenBucket{
+		maxBurst:   maxBurst,
+		lastRefill: now,
+	}
+}
+
+// Take refills the bucket with the given rate for the time that has passed since
+// the last call and takes all whole tokens out of the bucket. It returns the number
+// of taken tokens and the number of tokens that were discarded, because the bucket
+// was already full.
+func (b *TokenBucket) Take(now time.Time, ratePerSecond float64) (taken int, discarded int) {
+	elapsed := now.Sub(b.lastRefill)
+	b.lastRefill = now
+	if elapsed < 0 || ratePerSecond <= 0 {
+		return 0, 0
+	}
+
+	b.tokens += ratePerSecond * elapsed.Seconds()
+
+	capacity := math.Max(1, ratePerSecond*b.maxBurst.Seconds())
+	if b.tokens > capacity {
+		discarded = int(b.tokens - capacity)
+		b.tokens = capacity
+	}
+
+	taken = int(b.tokens)
+	b.tokens -= float64(taken)
+
+	return taken, discarded
+}
@@ -0,0 +1,66 @@
+package traffic
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucketTake(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		elapsed       []time.Duration
+		ratePerSecond float64
+		wantTaken     int
+		wantDiscarded int
+	}{
+		{
+			name:          "whole tokens are taken",
+			elapsed:       []time.Duration{time.Second},
+			ratePerSecond: 10,
+			wantTaken:     10,
+		},
+		{
+			name:          "fractions are kept for the next call",
+			elapsed:       []time.Duration{50 * time.Millisecond, 100 * time.Millisecond},
+			ratePerSecond: 10,
+			wantTaken:     1,
+		},
+		{
+			name:          "tokens beyond the burst are discarded",
+			elapsed:       []time.Duration{5 * time.Second},
+			ratePerSecond: 10,
+			wantTaken:     20,
+			wantDiscarded: 30,
+		},
+		{
+			name:          "at least one token fits into the bucket",
+			elapsed:       []time.Duration{10 * time.Second},
+			ratePerSecond: 0.1,
+			wantTaken:     1,
+		},
+		{
+			name:          "zero rate takes nothing",
+			elapsed:       []time.Duration{time.Second},
+			ratePerSecond: 0,
+		},
+		{
+			name:          "clock going backwards takes nothing",
+			elapsed:       []time.Duration{-time.Second},
+			ratePerSecond: 10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket := NewTokenBucket(2*time.Second, start)
+			var taken, discarded int
+			for _, elapsed := range tt.elapsed {
+				taken, discarded = bucket.Take(start.Add(elapsed), tt.ratePerSecond)
+			}
+			if taken != tt.wantTaken || discarded != tt.wantDiscarded {
+				t.Errorf("Take() = %v, %v, want %v, %v", taken, discarded, tt.wantTaken, tt.wantDiscarded)
+			}
+		})
+	}
+}
@@ -9,6 +9,16 @@ var (
 	CustomerV1 string
 	//go:embed shop/v1/customer.proto
 	CustomerV2 string
+	//go:embed shop/v1/customer_v3.proto
+	CustomerV3 string
+	//go:embed shop/v1/customer_v4.proto
+	CustomerV4 string
+	//go:embed shop/v1/customer_v5.proto
+	CustomerV5 string
 	//go:embed shop/v1/order.proto
 	Order string
+	//go:embed shop/v1/frontend_event.proto
+	FrontendEvent string
+	//go:embed shop/v1/key.proto
+	Key string
 )